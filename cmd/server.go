@@ -15,6 +15,7 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal"
 	"github.com/welovemedia/ffmate/v2/internal/cfg"
 	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/service/client"
 	"github.com/yosev/debugo"
 	"goyave.dev/goyave/v5"
 	"goyave.dev/goyave/v5/config"
@@ -33,6 +34,7 @@ func init() {
 	rootCmd.AddCommand(serverCmd)
 
 	serverCmd.Flags().String("ffmpeg", "", "path to ffmpeg binary")
+	serverCmd.Flags().String("ffprobe", "", "path to ffprobe binary (defaults to the one next to ffmpeg)")
 	serverCmd.Flags().Uint("port", 3000, "the port to listen to")
 	if runtime.GOOS == "windows" {
 		serverCmd.Flags().String("database", "%APPDATA%\\ffmate\\db.sql", "the path do the database")
@@ -44,8 +46,15 @@ func init() {
 	serverCmd.Flags().Bool("send-telemetry", true, "enable sending anonymous telemetry data")
 	serverCmd.Flags().Bool("no-ui", false, "do not open the ui in the browser")
 	serverCmd.Flags().String("identifier", "", "a unique client identifier (default to hostname)")
+	serverCmd.Flags().Uint("shutdown-timeout", 0, "seconds to wait for running tasks to finish on shutdown before they are requeued")
+	serverCmd.Flags().String("recover-orphaned", "requeue", "how to handle tasks left running by a previous run or an offline client (requeue or fail)")
+	serverCmd.Flags().StringSlice("labels", []string{}, "comma separated labels this client provides to tasks requiring them (e.g. gpu=nvenc,site=berlin)")
+	serverCmd.Flags().StringSlice("concurrency-limits", []string{}, "comma separated maximum concurrent tasks per tag on this client (e.g. hevc4k=1,audio=8)")
+	serverCmd.Flags().Uint("probe-timeout", 30, "seconds after which ffprobe is aborted when probing an input file (0 disables the timeout)")
+	serverCmd.Flags().Uint("client-offline-after", 60, "seconds after which a client that was not seen is marked offline, at least twice the 15s heartbeat (cluster only)")
 
 	_ = viper.BindPFlag("ffmpeg", serverCmd.Flags().Lookup("ffmpeg"))
+	_ = viper.BindPFlag("ffprobe", serverCmd.Flags().Lookup("ffprobe"))
 	_ = viper.BindPFlag("port", serverCmd.Flags().Lookup("port"))
 	_ = viper.BindPFlag("database", serverCmd.Flags().Lookup("database"))
 	_ = viper.BindPFlag("maxConcurrentTasks", serverCmd.Flags().Lookup("max-concurrent-tasks"))
@@ -53,6 +62,12 @@ func init() {
 	_ = viper.BindPFlag("sendTelemetry", serverCmd.Flags().Lookup("send-telemetry"))
 	_ = viper.BindPFlag("noUI", serverCmd.Flags().Lookup("no-ui"))
 	_ = viper.BindPFlag("identifier", serverCmd.Flags().Lookup("identifier"))
+	_ = viper.BindPFlag("shutdownTimeout", serverCmd.Flags().Lookup("shutdown-timeout"))
+	_ = viper.BindPFlag("recoverOrphaned", serverCmd.Flags().Lookup("recover-orphaned"))
+	_ = viper.BindPFlag("labels", serverCmd.Flags().Lookup("labels"))
+	_ = viper.BindPFlag("concurrencyLimits", serverCmd.Flags().Lookup("concurrency-limits"))
+	_ = viper.BindPFlag("probeTimeout", serverCmd.Flags().Lookup("probe-timeout"))
+	_ = viper.BindPFlag("clientOfflineAfter", serverCmd.Flags().Lookup("client-offline-after"))
 }
 
 func server(_ *cobra.Command, _ []string) {
@@ -98,8 +113,13 @@ func setupConfig() {
 	}
 
 	cfg.Set("ffmate.ffmpeg", viper.GetString("ffmpeg"))
+	cfg.Set("ffmate.ffprobe", viper.GetString("ffprobe"))
+	cfg.Set("ffmate.probeTimeout", viper.GetInt("probeTimeout"))
 	cfg.Set("ffmate.debug", viper.GetString("debug"))
 	cfg.Set("ffmate.maxConcurrentTasks", viper.GetInt("maxConcurrentTasks"))
+	cfg.Set("ffmate.shutdownTimeout", viper.GetInt("shutdownTimeout"))
+	cfg.Set("ffmate.recoverOrphaned", viper.GetString("recoverOrphaned"))
+	cfg.Set("ffmate.clientOfflineAfter", clientOfflineAfter())
 	cfg.Set("ffmate.database", viper.GetString("database"))
 	cfg.Set("ffmate.isTray", viper.GetBool("tray"))
 	cfg.Set("ffmate.isUI", !viper.GetBool("noUI"))
@@ -107,6 +127,8 @@ func setupConfig() {
 
 	cfg.Set("ffmate.isFFmpeg", false)
 	cfg.Set("ffmate.identifier", client)
+	cfg.Set("ffmate.labels", labels())
+	cfg.Set("ffmate.concurrencyLimits", concurrencyLimits())
 	cfg.Set("ffmate.session", uuid.NewString())
 	cfg.Set("ffmate.telemetry.send", viper.GetBool("sendTelemetry"))
 	cfg.Set("ffmate.telemetry.url", "https://telemetry.ffmate.io")
@@ -181,3 +203,40 @@ func setupGoyaveConfig() *config.Config {
 
 	return cfg
 }
+
+// labels returns the trimmed, non-empty labels of this client
+func labels() []string {
+	var labels = []string{}
+	for _, label := range viper.GetStringSlice("labels") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// clientOfflineAfter returns the grace period (in seconds) after which a client is marked offline;
+// it must span at least two heartbeats so healthy clients are not marked offline and lose their tasks
+func clientOfflineAfter() int {
+	seconds := viper.GetInt("clientOfflineAfter")
+	if minimum := int(2 * client.HeartbeatInterval.Seconds()); seconds < minimum {
+		debug.Log.Error("invalid client offline grace period of %ds (expected at least %ds, twice the client heartbeat)", seconds, minimum)
+		os.Exit(1)
+	}
+	return seconds
+}
+
+// concurrencyLimits returns the maximum concurrent tasks per tag of this client
+func concurrencyLimits() map[string]int {
+	var limits = map[string]int{}
+	for _, limit := range viper.GetStringSlice("concurrencyLimits") {
+		tag, value, _ := strings.Cut(limit, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if strings.TrimSpace(tag) == "" || err != nil || n < 0 {
+			debug.Log.Error("invalid concurrency limit '%s' (expected tag=n)", limit)
+			os.Exit(1)
+		}
+		limits[strings.TrimSpace(tag)] = n
+	}
+	return limits
+}
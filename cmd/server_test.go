@@ -28,6 +28,8 @@ func TestSetupConfig(t *testing.T) {
 	assert.Equal(t, "/usr/bin/ffmpeg", cfg.GetString("ffmate.ffmpeg"))
 	assert.Equal(t, "info:*", cfg.GetString("ffmate.debug"))
 	assert.Equal(t, 5, cfg.GetInt("ffmate.maxConcurrentTasks"))
+	assert.Equal(t, 60, cfg.GetInt("ffmate.clientOfflineAfter"))
+	assert.Equal(t, 30, cfg.GetInt("ffmate.probeTimeout"))
 	assert.Equal(t, "postgresql://localhost:5432/testdb", cfg.GetString("ffmate.database"))
 	assert.True(t, cfg.GetBool("ffmate.isCluster"))
 	assert.True(t, cfg.GetBool("ffmate.isTray"))
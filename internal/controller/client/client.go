@@ -14,6 +14,7 @@ import (
 
 type Service interface {
 	List(page int, perPage int) (*[]model.Client, int64, error)
+	SetQueueStatus(identifier string, status dto.QueueStatus) error
 }
 
 type Controller struct {
@@ -29,6 +30,8 @@ func (c *Controller) Init(server *goyave.Server) {
 
 func (c *Controller) RegisterRoutes(router *goyave.Router) {
 	router.Get("/clients", c.list)
+	router.Patch("/clients/queue", c.updateQueue).ValidateBody(c.NewQueueRequest)
+	router.Patch("/clients/{identifier}/queue", c.updateQueue).ValidateBody(c.NewQueueRequest)
 }
 
 // @Summary List all clients
@@ -58,3 +61,24 @@ func (c *Controller) list(response *goyave.Response, request *goyave.Request) {
 
 	response.JSON(200, clientDTOs)
 }
+
+// @Summary Update the queue of clients
+// @Description Pause, drain or resume picking up new tasks on a single client (or all clients of the cluster if no identifier is given); draining clients finish their running tasks and become drained
+// @Tags clients
+// @Accept json
+// @Param identifier path string false "the clients identifier"
+// @Param request body dto.NewQueue true "new queue status"
+// @Produce json
+// @Success 200 {object} dto.Queue
+// @Router /clients/{identifier}/queue [patch]
+func (c *Controller) updateQueue(response *goyave.Response, request *goyave.Request) {
+	newQueue := typeutil.MustConvert[*dto.NewQueue](request.Data)
+	identifier := request.RouteParams["identifier"]
+
+	if err := c.clientService.SetQueueStatus(identifier, newQueue.Status); err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/clients#queue"))
+		return
+	}
+
+	response.JSON(200, &dto.Queue{Status: newQueue.Status})
+}
@@ -0,0 +1,14 @@
+package client
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"goyave.dev/goyave/v5"
+	v "goyave.dev/goyave/v5/validation"
+)
+
+func (c *Controller) NewQueueRequest(_ *goyave.Request) v.RuleSet {
+	return v.RuleSet{
+		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
+		{Path: "status", Rules: v.List{v.String(), v.Required(), v.In([]string{string(dto.QueueActive), string(dto.QueuePaused), string(dto.QueueDraining)})}},
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/controller/client"
 	"github.com/welovemedia/ffmate/v2/internal/controller/debug"
 	"github.com/welovemedia/ffmate/v2/internal/controller/health"
+	"github.com/welovemedia/ffmate/v2/internal/controller/janitor"
 	"github.com/welovemedia/ffmate/v2/internal/controller/preset"
+	"github.com/welovemedia/ffmate/v2/internal/controller/probe"
 	"github.com/welovemedia/ffmate/v2/internal/controller/prometheus"
+	"github.com/welovemedia/ffmate/v2/internal/controller/schedule"
 	"github.com/welovemedia/ffmate/v2/internal/controller/settings"
 	"github.com/welovemedia/ffmate/v2/internal/controller/swagger"
 	"github.com/welovemedia/ffmate/v2/internal/controller/task"
@@ -29,8 +32,11 @@ func Register(_ *goyave.Server, router *goyave.Router) {
 	apiRouter.Controller(&preset.Controller{})
 	apiRouter.Controller(&webhook.Controller{})
 	apiRouter.Controller(&watchfolder.Controller{})
+	apiRouter.Controller(&schedule.Controller{})
 	apiRouter.Controller(&task.Controller{})
 	apiRouter.Controller(&settings.Controller{})
+	apiRouter.Controller(&janitor.Controller{})
+	apiRouter.Controller(&probe.Controller{})
 	apiRouter.Controller(&client.Controller{})
 	apiRouter.Controller(&debug.Controller{})
 
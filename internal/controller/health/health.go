@@ -3,15 +3,23 @@ package health
 import (
 	"github.com/welovemedia/ffmate/v2/internal/debug"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/client"
 	"goyave.dev/goyave/v5"
 )
 
+type TaskService interface {
+	RunningTasks() int
+}
+
 type Controller struct {
 	goyave.Component
+	taskService TaskService
 }
 
 func (c *Controller) Init(server *goyave.Server) {
 	c.Component.Init(server)
+	c.taskService = server.Service(service.Task).(TaskService)
 	debug.Controller.Debug("registered health controller")
 }
 
@@ -20,7 +28,13 @@ func (c *Controller) RegisterRoutes(router *goyave.Router) {
 }
 
 func (c *Controller) get(response *goyave.Response, _ *goyave.Request) {
-	status := &dto.Health{Status: dto.HealthError}
+	status := &dto.Health{
+		Status:       dto.HealthError,
+		Queue:        client.QueueStatus(),
+		RunningTasks: c.taskService.RunningTasks(),
+	}
+	status.Drained = status.Queue == dto.QueueDrained
+
 	statusCode := 500
 	if c.Server().IsReady() {
 		status.Status = dto.HealthOk
@@ -0,0 +1,61 @@
+package janitor
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/exception"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"goyave.dev/goyave/v5"
+)
+
+type Service interface {
+	Run(dryRun bool) (*dto.JanitorReport, error)
+}
+
+type Controller struct {
+	goyave.Component
+	janitorService Service
+}
+
+func (c *Controller) Init(server *goyave.Server) {
+	c.Component.Init(server)
+	c.janitorService = server.Service(service.Janitor).(Service)
+	debug.Controller.Debug("registered janitor controller")
+}
+
+func (c *Controller) RegisterRoutes(router *goyave.Router) {
+	router.Get("/janitor/dry-run", c.dryRun)
+	router.Post("/janitor/run", c.run)
+}
+
+// @Summary Dry-run the janitor
+// @Description Report how many rows would be purged by the current retention settings without deleting anything
+// @Tags janitor
+// @Produce json
+// @Success 200 {object} dto.JanitorReport
+// @Router /janitor/dry-run [get]
+func (c *Controller) dryRun(response *goyave.Response, _ *goyave.Request) {
+	report, err := c.janitorService.Run(true)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/settings#retention"))
+		return
+	}
+
+	response.JSON(200, report)
+}
+
+// @Summary Run the janitor
+// @Description Purge all rows matching the current retention settings immediately
+// @Tags janitor
+// @Produce json
+// @Success 200 {object} dto.JanitorReport
+// @Router /janitor/run [post]
+func (c *Controller) run(response *goyave.Response, _ *goyave.Request) {
+	report, err := c.janitorService.Run(false)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/settings#retention"))
+		return
+	}
+
+	response.JSON(200, report)
+}
@@ -1,6 +1,7 @@
 package preset
 
 import (
+	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/validate"
 	"goyave.dev/goyave/v5"
 	v "goyave.dev/goyave/v5/validation"
@@ -11,12 +12,29 @@ func (c *Controller) NewPresetRequest(_ *goyave.Request) v.RuleSet {
 		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
 		{Path: "name", Rules: v.List{v.String(), v.Required()}},
 		{Path: "description", Rules: v.List{v.String()}},
-		{Path: "command", Rules: v.List{v.String(), v.Required()}},
+		{Path: "command", Rules: v.List{
+			v.String(),
+			v.WithMessage(v.RequiredIf(func(ctx *v.Context) bool {
+				data, ok := ctx.Parent.(map[string]any)
+				if !ok {
+					return false
+				}
+				rules, ok := data["rules"].([]any)
+				return !ok || len(rules) == 0
+			}), "Command is required for presets without rules"),
+		}},
 		{Path: "priority", Rules: v.List{v.Uint()}},
 		{Path: "outputFile", Rules: v.List{v.String()}},
 		{Path: "webhooks", Rules: v.List{v.Array()}},
 		{Path: "webhooks[].url", Rules: v.List{validate.PreserveValue(v.URL()), v.Required()}},
 		{Path: "webhooks[].event", Rules: v.List{v.String(), v.Required()}},
+		{Path: "webhooks[].headers", Rules: v.List{v.Object()}},
+		{Path: "webhooks[].headers.*", Rules: v.List{v.String()}},
+		{Path: "webhooks[].secret", Rules: v.List{v.String()}},
+		{Path: "webhooks[].template", Rules: v.List{v.String(), validate.Template()}},
+		{Path: "webhooks[].contentType", Rules: v.List{v.String()}},
+		{Path: "webhooks[].timeout", Rules: v.List{v.Uint(), v.Between(1, 120)}},
+		{Path: "webhooks[].maxAttempts", Rules: v.List{v.Uint(), v.Between(1, 50)}},
 		{Path: "preProcessing", Rules: v.List{v.Object()}},
 		{Path: "preProcessing.scriptPath", Rules: v.List{v.String()}},
 		{Path: "preProcessing.sidecarPath", Rules: v.List{v.String()}},
@@ -24,6 +42,33 @@ func (c *Controller) NewPresetRequest(_ *goyave.Request) v.RuleSet {
 		{Path: "postProcessing", Rules: v.List{v.Object()}},
 		{Path: "postProcessing.scriptPath", Rules: v.List{v.String()}},
 		{Path: "postProcessing.sidecarPath", Rules: v.List{v.String()}},
+		{Path: "retry", Rules: v.List{v.Object()}},
+		{Path: "retry.maxAttempts", Rules: v.List{v.Uint(), v.Required(), v.Max(100)}},
+		{Path: "retry.delay", Rules: v.List{v.Uint(), v.Max(86400)}},
+		{Path: "retry.backoff", Rules: v.List{v.Float64(), v.Min(1)}},
+		{Path: "retry.match", Rules: v.List{v.String(), validate.Regexp()}},
+		{Path: "labels", Rules: v.List{v.Array()}},
+		{Path: "labels[]", Rules: v.List{v.String(), v.Required()}},
+		{Path: "tags", Rules: v.List{v.Array()}},
+		{Path: "tags[]", Rules: v.List{v.String(), v.Required()}},
+		{Path: "weight", Rules: v.List{v.Uint()}},
+		{Path: "maxConcurrent", Rules: v.List{v.Uint()}},
 		{Path: "globalPresetName", Rules: v.List{v.String()}},
+		{Path: "rules", Rules: v.List{v.Array()}},
+		{Path: "rules[]", Rules: v.List{v.Object()}},
+		{Path: "rules[].preset", Rules: v.List{v.String(), v.Required()}},
+		{Path: "rules[].conditions", Rules: v.List{v.Array()}},
+		{Path: "rules[].conditions[]", Rules: v.List{v.Object()}},
+		{Path: "rules[].conditions[].field", Rules: v.List{v.String(), v.Required()}},
+		{Path: "rules[].conditions[].operator", Rules: v.List{v.String(), v.Required(), v.In(conditionOperators)}},
+		{Path: "rules[].conditions[].value", Rules: v.List{v.RequiredIf(func(ctx *v.Context) bool {
+			data, ok := ctx.Parent.(map[string]any)
+			return ok && data["operator"] != string(dto.ConditionExists) && data["operator"] != string(dto.ConditionNotExists)
+		})}},
 	}
 }
+
+var conditionOperators = []string{
+	string(dto.ConditionEq), string(dto.ConditionNe), string(dto.ConditionGt), string(dto.ConditionGte), string(dto.ConditionLt), string(dto.ConditionLte),
+	string(dto.ConditionContains), string(dto.ConditionMatches), string(dto.ConditionExists), string(dto.ConditionNotExists),
+}
@@ -0,0 +1,61 @@
+package probe
+
+import (
+	"context"
+
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/exception"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/typeutil"
+	v "goyave.dev/goyave/v5/validation"
+)
+
+type Service interface {
+	Probe(ctx context.Context, path string) (*dto.Probe, error)
+}
+
+type Controller struct {
+	goyave.Component
+	ffmpegService Service
+}
+
+func (c *Controller) Init(server *goyave.Server) {
+	c.Component.Init(server)
+	c.ffmpegService = server.Service(service.FFMpeg).(Service)
+	debug.Controller.Debug("registered probe controller")
+}
+
+func (c *Controller) RegisterRoutes(router *goyave.Router) {
+	router.Post("/probe", c.probe).ValidateBody(c.NewProbeRequest)
+}
+
+func (c *Controller) NewProbeRequest(_ *goyave.Request) v.RuleSet {
+	return v.RuleSet{
+		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
+		{Path: "inputFile", Rules: v.List{v.String(), v.Required()}},
+	}
+}
+
+// @Summary Probe a media file
+// @Description Read the format and stream information of a media file using ffprobe
+// @Tags probe
+// @Accept json
+// @Param request body dto.NewProbe true "file to probe"
+// @Produce json
+// @Success 200 {object} dto.Probe
+// @Router /probe [post]
+func (c *Controller) probe(response *goyave.Response, request *goyave.Request) {
+	newProbe := typeutil.MustConvert[*dto.NewProbe](request.Data)
+
+	probe, err := c.ffmpegService.Probe(request.Context(), newProbe.InputFile)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/probe"))
+		return
+	}
+
+	metrics.Gauge("probe.executed").Inc()
+	response.JSON(200, probe)
+}
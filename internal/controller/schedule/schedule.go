@@ -0,0 +1,147 @@
+package schedule
+
+import (
+	"fmt"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/exception"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/validate"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/typeutil"
+)
+
+type Service interface {
+	List(page int, perPage int) (*[]model.Schedule, int64, error)
+	Add(schedule *dto.NewSchedule) (*model.Schedule, error)
+	Delete(uuid string) error
+	Get(uuid string) (*model.Schedule, error)
+	Update(uuid string, schedule *dto.NewSchedule) (*model.Schedule, error)
+}
+
+type Controller struct {
+	goyave.Component
+	scheduleService Service
+}
+
+func (c *Controller) Init(server *goyave.Server) {
+	c.Component.Init(server)
+	c.scheduleService = server.Service(service.Schedule).(Service)
+	debug.Controller.Debug("registered schedule controller")
+}
+
+func (c *Controller) RegisterRoutes(router *goyave.Router) {
+	router.Delete("/schedules/{uuid}", c.delete)
+	router.Post("/schedules", c.add).ValidateBody(c.NewScheduleRequest)
+	router.Put("/schedules/{uuid}", c.update).ValidateBody(c.NewScheduleRequest)
+	router.Get("/schedules", c.list).ValidateQuery(validate.PaginationRequest)
+	router.Get("/schedules/{uuid}", c.get)
+}
+
+// @Summary Delete a schedule
+// @Description Delete a schedule by its uuid
+// @Tags schedule
+// @Param uuid path string true "the schedules uuid"
+// @Produce json
+// @Success 204
+// @Router /schedules/{uuid} [delete]
+func (c *Controller) delete(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+	err := c.scheduleService.Delete(uuid)
+
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/schedule#deleting-a-schedule"))
+		return
+	}
+
+	response.Status(204)
+}
+
+// @Summary List all schedules
+// @Description List all existing schedules
+// @Tags schedule
+// @Produce json
+// @Success 200 {object} []dto.Schedule
+// @Router /schedules [get]
+func (c *Controller) list(response *goyave.Response, request *goyave.Request) {
+	query := typeutil.MustConvert[*dto.Pagination](request.Query)
+
+	schedule, total, err := c.scheduleService.List(query.Page.Default(0), query.PerPage.Default(100))
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/schedule#listing-schedules"))
+		return
+	}
+
+	response.Header().Set("X-Total", fmt.Sprintf("%d", total))
+
+	// Transform each schedule to its DTO
+	var scheduleDTOs = []dto.Schedule{}
+	for _, schedule := range *schedule {
+		scheduleDTOs = append(scheduleDTOs, *schedule.ToDTO())
+	}
+
+	response.JSON(200, scheduleDTOs)
+}
+
+// @Summary Add a new schedule
+// @Description Add a new schedule; the input file may be a glob pattern (e.g. /archive/*.mov) creating a task for every matching file on each run
+// @Tags schedule
+// @Accept json
+// @Param request body dto.NewSchedule true "new schedule"
+// @Produce json
+// @Success 200 {object} dto.Schedule
+// @Router /schedules [post]
+func (c *Controller) add(response *goyave.Response, request *goyave.Request) {
+	newSchedule := typeutil.MustConvert[*dto.NewSchedule](request.Data)
+
+	schedule, err := c.scheduleService.Add(newSchedule)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/schedule#creating-a-schedule"))
+		return
+	}
+
+	response.JSON(200, schedule.ToDTO())
+}
+
+// @Summary Get single schedule
+// @Description	Get a single schedule by its uuid
+// @Tags schedule
+// @Param uuid path string true "the schedules uuid"
+// @Produce json
+// @Success 200 {object} dto.Schedule
+// @Router /schedules/{uuid} [get]
+func (c *Controller) get(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+
+	schedule, err := c.scheduleService.Get(uuid)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/schedule#getting-a-single-schedule"))
+		return
+	}
+
+	response.JSON(200, schedule.ToDTO())
+}
+
+// @Summary Update a schedule
+// @Description Update a schedule
+// @Tags schedule
+// @Accept json
+// @Param uuid path string true "the schedules uuid"
+// @Param request body dto.NewSchedule true "new schedule"
+// @Produce json
+// @Success 200 {object} dto.Schedule
+// @Router /schedules/{uuid} [put]
+func (c *Controller) update(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+	newSchedule := typeutil.MustConvert[*dto.NewSchedule](request.Data)
+
+	schedule, err := c.scheduleService.Update(uuid, newSchedule)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/schedule#updating-a-schedule"))
+		return
+	}
+
+	response.JSON(200, schedule.ToDTO())
+}
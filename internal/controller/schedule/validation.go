@@ -0,0 +1,22 @@
+package schedule
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/validate"
+	"goyave.dev/goyave/v5"
+	v "goyave.dev/goyave/v5/validation"
+)
+
+func (c *Controller) NewScheduleRequest(_ *goyave.Request) v.RuleSet {
+	return v.RuleSet{
+		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
+		{Path: "name", Rules: v.List{v.String(), v.Required()}},
+		{Path: "description", Rules: v.List{v.String()}},
+		{Path: "cron", Rules: v.List{v.String(), v.Required(), validate.Cron()}},
+		{Path: "preset", Rules: v.List{v.String(), v.Required()}},
+		{Path: "inputFile", Rules: v.List{v.String()}},
+		{Path: "outputFile", Rules: v.List{v.String()}},
+		{Path: "metadata", Rules: v.List{v.Object()}},
+		{Path: "priority", Rules: v.List{v.Uint()}},
+		{Path: "suspended", Rules: v.List{v.Bool()}},
+	}
+}
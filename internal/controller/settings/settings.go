@@ -28,7 +28,7 @@ func (c *Controller) Init(server *goyave.Server) {
 
 func (c *Controller) RegisterRoutes(router *goyave.Router) {
 	router.Get("/settings", c.load)
-	router.Post("/settings", c.save)
+	router.Post("/settings", c.save).ValidateBody(c.SettingsRequest)
 }
 
 // @Summary Get all settings
@@ -48,7 +48,7 @@ func (c *Controller) load(response *goyave.Response, _ *goyave.Request) {
 }
 
 // @Summary Save all settings
-// @Description	Save all existing settings
+// @Description	Save all settings and apply them on all cluster members without a restart
 // @Tags settings
 // @Accept json
 // @Param request body dto.Settings true "save settings"
@@ -0,0 +1,26 @@
+package settings
+
+import (
+	"goyave.dev/goyave/v5"
+	v "goyave.dev/goyave/v5/validation"
+)
+
+func (c *Controller) SettingsRequest(_ *goyave.Request) v.RuleSet {
+	return v.RuleSet{
+		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
+		{Path: "maxConcurrentTasks", Rules: v.List{v.Uint(), v.Min(1)}},
+		{Path: "defaultPriority", Rules: v.List{v.Uint()}},
+		{Path: "retention", Rules: v.List{v.Object()}},
+		{Path: "retention.doneSuccessful", Rules: v.List{v.Uint(), v.Max(36500)}},
+		{Path: "retention.doneError", Rules: v.List{v.Uint(), v.Max(36500)}},
+		{Path: "retention.doneCanceled", Rules: v.List{v.Uint(), v.Max(36500)}},
+		{Path: "retention.deleted", Rules: v.List{v.Uint(), v.Max(36500)}},
+		{Path: "retention.webhookExecutions", Rules: v.List{v.Uint()}},
+		{Path: "retention.deadLetters", Rules: v.List{v.Uint(), v.Max(36500)}},
+		{Path: "nodes", Rules: v.List{v.Array()}},
+		{Path: "nodes[]", Rules: v.List{v.Object()}},
+		{Path: "nodes[].identifier", Rules: v.List{v.String(), v.Required()}},
+		{Path: "nodes[].maxConcurrentTasks", Rules: v.List{v.Uint(), v.Min(1)}},
+		{Path: "nodes[].defaultPriority", Rules: v.List{v.Uint()}},
+	}
+}
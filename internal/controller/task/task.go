@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/welovemedia/ffmate/v2/internal/database/model"
@@ -14,14 +15,25 @@ import (
 )
 
 type Service interface {
-	List(page int, perPage int) (*[]model.Task, int64, error)
+	List(page int, perPage int, filter *dto.TaskFilter) (*[]model.Task, int64, error)
 	GetBatch(uuid string, page int, perPage int) (*dto.Batch, int64, error)
-	Add(task *dto.NewTask, source dto.TaskSource, batch string) (*model.Task, error)
-	AddBatch(btach *dto.NewBatch) (*dto.Batch, error)
+	Add(ctx context.Context, task *dto.NewTask, source dto.TaskSource, batch string) (*model.Task, error)
+	AddBatch(ctx context.Context, btach *dto.NewBatch) (*dto.Batch, error)
+	AddWorkflow(ctx context.Context, workflow *dto.NewWorkflow) (*dto.Batch, error)
 	Delete(uuid string) error
 	Get(uuid string) (*model.Task, error)
-	Cancel(uuid string) (*model.Task, error)
+	Cancel(uuid string, cleanup bool) (*model.Task, error)
 	Restart(uuid string) (*model.Task, error)
+	Pause(uuid string) (*model.Task, error)
+	Resume(uuid string) (*model.Task, error)
+	Logs(uuid string, page int, perPage int) (*[]model.TaskLog, int64, error)
+	Edit(uuid string, update *dto.UpdateTask) (*model.Task, error)
+	Resolve(ctx context.Context, task *dto.NewTask) (*dto.ResolvedTask, error)
+	Bulk(operation *dto.NewBulkOperation) (*dto.BulkOperation, error)
+	ListBatches(page int, perPage int) (*[]dto.Batch, int64, error)
+	CancelBatch(uuid string, cleanup bool) (*dto.BulkOperation, error)
+	RestartBatch(uuid string) (*dto.BulkOperation, error)
+	DeleteBatch(uuid string) (*dto.BulkOperation, error)
 }
 
 type Controller struct {
@@ -38,13 +50,25 @@ func (c *Controller) Init(server *goyave.Server) {
 func (c *Controller) RegisterRoutes(router *goyave.Router) {
 	router.Delete("/tasks/{uuid}", c.delete)
 	router.Post("/tasks", c.add).ValidateBody(c.NewTaskRequest)
-	router.Get("/tasks", c.list).ValidateQuery(validate.PaginationRequest)
+	router.Get("/tasks", c.list).ValidateQuery(c.TaskFilterRequest)
+	router.Post("/tasks/bulk", c.bulk).ValidateBody(c.BulkOperationRequest)
+	router.Post("/tasks/resolve", c.resolve).ValidateBody(c.NewTaskRequest)
 	router.Get("/tasks/{uuid}", c.get)
-	router.Patch("/tasks/{uuid}/cancel", c.cancel)
+	router.Patch("/tasks/{uuid}", c.edit).ValidateBody(c.UpdateTaskRequest)
+	router.Get("/tasks/{uuid}/logs", c.logs).ValidateQuery(validate.PaginationRequest)
+	router.Patch("/tasks/{uuid}/cancel", c.cancel).ValidateQuery(c.CancelTaskRequest)
 	router.Patch("/tasks/{uuid}/restart", c.restart)
+	router.Patch("/tasks/{uuid}/pause", c.pause)
+	router.Patch("/tasks/{uuid}/resume", c.resume)
 
 	router.Post("/batches", c.addBatch)
+	router.Get("/batches", c.listBatches).ValidateQuery(validate.PaginationRequest)
 	router.Get("/batches/{uuid}", c.getBatch).ValidateQuery(validate.PaginationRequest)
+	router.Delete("/batches/{uuid}", c.deleteBatch)
+	router.Patch("/batches/{uuid}/cancel", c.cancelBatch).ValidateQuery(c.CancelTaskRequest)
+	router.Patch("/batches/{uuid}/restart", c.restartBatch)
+
+	router.Post("/workflows", c.addWorkflow).ValidateBody(c.NewWorkflowRequest)
 }
 
 // @Summary Delete a task
@@ -67,17 +91,31 @@ func (c *Controller) delete(response *goyave.Response, request *goyave.Request)
 }
 
 // @Summary List all tasks
-// @Description List all existing tasks
+// @Description List all existing tasks, optionally filtered and sorted
 // @Tags tasks
 // @Param page query int false "the page of a pagination request (min 0)"
 // @Param perPage query int false "the amount of results of a pagination request (min 1; max: 100)"
+// @Param status query []string false "only tasks with one of the given statuses" collectionFormat(multi)
+// @Param source query []string false "only tasks with one of the given sources" collectionFormat(multi)
+// @Param batch query string false "only tasks of the given batch"
+// @Param preset query string false "only tasks created from the given preset"
+// @Param client query string false "only tasks processed by the given client identifier"
+// @Param watchfolder query string false "only tasks created by the given watchfolder uuid"
+// @Param search query string false "case-insensitive substring of the name or input file"
+// @Param metadata query []string false "only tasks with the given metadata (key:value, nested keys separated by dots)" collectionFormat(multi)
+// @Param createdFrom query int false "only tasks created at or after (unix milliseconds)"
+// @Param createdTo query int false "only tasks created at or before (unix milliseconds)"
+// @Param finishedFrom query int false "only tasks finished at or after (unix milliseconds)"
+// @Param finishedTo query int false "only tasks finished at or before (unix milliseconds)"
+// @Param sort query string false "the field to sort by (createdAt, updatedAt, startedAt, finishedAt, priority, progress, name, status; default: createdAt)"
+// @Param order query string false "the sort order (asc, desc; default: desc)"
 // @Produce json
 // @Success 200 {object} []dto.Task
 // @Router /tasks [get]
 func (c *Controller) list(response *goyave.Response, request *goyave.Request) {
-	query := typeutil.MustConvert[*dto.Pagination](request.Query)
+	query := typeutil.MustConvert[*dto.TaskFilter](request.Query)
 
-	tasks, total, err := c.taskService.List(query.Page.Default(0), query.PerPage.Default(100))
+	tasks, total, err := c.taskService.List(query.Page.Default(0), query.PerPage.Default(100), query)
 	if err != nil {
 		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#monitoring-a-task"))
 		return
@@ -94,6 +132,102 @@ func (c *Controller) list(response *goyave.Response, request *goyave.Request) {
 	response.JSON(200, taskDTOs)
 }
 
+// @Summary Apply a bulk operation
+// @Description Cancel, restart, delete or change the priority of all tasks selected by uuid and/or filter; a filter needs at least one criterion
+// @Tags tasks
+// @Accept json
+// @Param request body dto.NewBulkOperation true "bulk operation"
+// @Produce json
+// @Success 200 {object} dto.BulkOperation
+// @Router /tasks/bulk [post]
+func (c *Controller) bulk(response *goyave.Response, request *goyave.Request) {
+	operation := typeutil.MustConvert[*dto.NewBulkOperation](request.Data)
+
+	result, err := c.taskService.Bulk(operation)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#bulk-operations"))
+		return
+	}
+
+	response.JSON(200, result)
+}
+
+// @Summary List all batches
+// @Description List all batches including their status summary
+// @Tags tasks
+// @Param page query int false "the page of a pagination request (min 0)"
+// @Param perPage query int false "the amount of results of a pagination request (min 1; max: 100)"
+// @Produce json
+// @Success 200 {object} []dto.Batch
+// @Router /batches [get]
+func (c *Controller) listBatches(response *goyave.Response, request *goyave.Request) {
+	query := typeutil.MustConvert[*dto.Pagination](request.Query)
+
+	batches, total, err := c.taskService.ListBatches(query.Page.Default(0), query.PerPage.Default(100))
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#monitoring-all-tasks"))
+		return
+	}
+
+	response.Header().Set("X-Total", fmt.Sprintf("%d", total))
+
+	response.JSON(200, batches)
+}
+
+// @Summary Cancel a batch
+// @Description Cancel all unfinished tasks of a batch
+// @Tags tasks
+// @Param uuid path string true "the batch uuid"
+// @Param cleanup query bool false "remove the partially written output files of running tasks"
+// @Produce json
+// @Success 200 {object} dto.BulkOperation
+// @Router /batches/{uuid}/cancel [patch]
+func (c *Controller) cancelBatch(response *goyave.Response, request *goyave.Request) {
+	query := typeutil.MustConvert[*dto.CancelTask](request.Query)
+
+	result, err := c.taskService.CancelBatch(request.RouteParams["uuid"], query.Cleanup.Default(false))
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#batch-operations"))
+		return
+	}
+
+	response.JSON(200, result)
+}
+
+// @Summary Restart a batch
+// @Description Restart all tasks of a batch
+// @Tags tasks
+// @Param uuid path string true "the batch uuid"
+// @Produce json
+// @Success 200 {object} dto.BulkOperation
+// @Router /batches/{uuid}/restart [patch]
+func (c *Controller) restartBatch(response *goyave.Response, request *goyave.Request) {
+	result, err := c.taskService.RestartBatch(request.RouteParams["uuid"])
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#batch-operations"))
+		return
+	}
+
+	response.JSON(200, result)
+}
+
+// @Summary Delete a batch
+// @Description Delete all tasks of a batch
+// @Tags tasks
+// @Param uuid path string true "the batch uuid"
+// @Produce json
+// @Success 200 {object} dto.BulkOperation
+// @Router /batches/{uuid} [delete]
+func (c *Controller) deleteBatch(response *goyave.Response, request *goyave.Request) {
+	result, err := c.taskService.DeleteBatch(request.RouteParams["uuid"])
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#batch-operations"))
+		return
+	}
+
+	response.JSON(200, result)
+}
+
 // @Summary Get a btach
 // @Description	Get a batch by uuid
 // @Tags tasks
@@ -127,7 +261,7 @@ func (c *Controller) getBatch(response *goyave.Response, request *goyave.Request
 func (c *Controller) add(response *goyave.Response, request *goyave.Request) {
 	newTask := typeutil.MustConvert[*dto.NewTask](request.Data)
 
-	preset, err := c.taskService.Add(newTask, dto.API, "")
+	preset, err := c.taskService.Add(request.Context(), newTask, dto.API, "")
 	if err != nil {
 		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#creating-a-task"))
 		return
@@ -136,6 +270,26 @@ func (c *Controller) add(response *goyave.Response, request *goyave.Request) {
 	response.JSON(200, preset.ToDTO())
 }
 
+// @Summary Resolve a task
+// @Description Resolve the command, files and processing paths of a new task (including its preset and all wildcards) without queuing it
+// @Tags tasks
+// @Accept json
+// @Param request body dto.NewTask true "new task"
+// @Produce json
+// @Success 200 {object} dto.ResolvedTask
+// @Router /tasks/resolve [post]
+func (c *Controller) resolve(response *goyave.Response, request *goyave.Request) {
+	newTask := typeutil.MustConvert[*dto.NewTask](request.Data)
+
+	resolved, err := c.taskService.Resolve(request.Context(), newTask)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#resolving-a-task"))
+		return
+	}
+
+	response.JSON(200, resolved)
+}
+
 // @Summary Add a batch of tasks
 // @Description	Add a batch of new tasks to the queue
 // @Tags tasks
@@ -147,7 +301,7 @@ func (c *Controller) add(response *goyave.Response, request *goyave.Request) {
 func (c *Controller) addBatch(response *goyave.Response, request *goyave.Request) {
 	newBatch := typeutil.MustConvert[*dto.NewBatch](request.Data)
 
-	batch, err := c.taskService.AddBatch(newBatch)
+	batch, err := c.taskService.AddBatch(request.Context(), newBatch)
 	if err != nil {
 		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#submitting-multiple-tasks-as-a-batch"))
 		return
@@ -156,6 +310,26 @@ func (c *Controller) addBatch(response *goyave.Response, request *goyave.Request
 	response.JSON(200, batch)
 }
 
+// @Summary Add a workflow of tasks
+// @Description	Add a workflow of new tasks to the queue, where tasks may depend on each other by their id
+// @Tags tasks
+// @Accept json
+// @Param request body dto.NewWorkflow true "new workflow"
+// @Produce json
+// @Success 200 {object} dto.Batch
+// @Router /workflows [post]
+func (c *Controller) addWorkflow(response *goyave.Response, request *goyave.Request) {
+	newWorkflow := typeutil.MustConvert[*dto.NewWorkflow](request.Data)
+
+	batch, err := c.taskService.AddWorkflow(request.Context(), newWorkflow)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#workflows"))
+		return
+	}
+
+	response.JSON(200, batch)
+}
+
 // @Summary Get single task
 // @Description	Get a single task by its uuid
 // @Tags tasks
@@ -175,17 +349,40 @@ func (c *Controller) get(response *goyave.Response, request *goyave.Request) {
 	response.JSON(200, task.ToDTO())
 }
 
+// @Summary Edit a task
+// @Description Edit a queued task by its uuid; the priority of started tasks can be changed until they are finished
+// @Tags tasks
+// @Accept json
+// @Param uuid path string true "the tasks uuid"
+// @Param request body dto.UpdateTask true "changed fields"
+// @Produce json
+// @Success 200 {object} dto.Task
+// @Router /tasks/{uuid} [patch]
+func (c *Controller) edit(response *goyave.Response, request *goyave.Request) {
+	update := typeutil.MustConvert[*dto.UpdateTask](request.Data)
+
+	task, err := c.taskService.Edit(request.RouteParams["uuid"], update)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#editing-a-task"))
+		return
+	}
+
+	response.JSON(200, task.ToDTO())
+}
+
 // @Summary Cancel a task
 // @Description Cancel a task by its uuid
 // @Tags tasks
 // @Param uuid path string true "the tasks uuid"
+// @Param cleanup query bool false "remove the partially written output file of a running task"
 // @Produce json
 // @Success 200 {object} dto.Task
 // @Router /tasks/{uuid}/cancel [patch]
 func (c *Controller) cancel(response *goyave.Response, request *goyave.Request) {
+	query := typeutil.MustConvert[*dto.CancelTask](request.Query)
 	uuid := request.RouteParams["uuid"]
 
-	task, err := c.taskService.Cancel(uuid)
+	task, err := c.taskService.Cancel(uuid, query.Cleanup.Default(false))
 	if err != nil {
 		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#canceling-a-task"))
 		return
@@ -194,6 +391,44 @@ func (c *Controller) cancel(response *goyave.Response, request *goyave.Request)
 	response.JSON(200, task.ToDTO())
 }
 
+// @Summary Pause a task
+// @Description Pause a queued or running task by its uuid
+// @Tags tasks
+// @Param uuid path string true "the tasks uuid"
+// @Produce json
+// @Success 200 {object} dto.Task
+// @Router /tasks/{uuid}/pause [patch]
+func (c *Controller) pause(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+
+	task, err := c.taskService.Pause(uuid)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#pausing-a-task"))
+		return
+	}
+
+	response.JSON(200, task.ToDTO())
+}
+
+// @Summary Resume a task
+// @Description Resume a paused task by its uuid
+// @Tags tasks
+// @Param uuid path string true "the tasks uuid"
+// @Produce json
+// @Success 200 {object} dto.Task
+// @Router /tasks/{uuid}/resume [patch]
+func (c *Controller) resume(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+
+	task, err := c.taskService.Resume(uuid)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#pausing-a-task"))
+		return
+	}
+
+	response.JSON(200, task.ToDTO())
+}
+
 // @Summary Restart a task
 // @Description Restart a task by its uuid
 // @Tags tasks
@@ -212,3 +447,33 @@ func (c *Controller) restart(response *goyave.Response, request *goyave.Request)
 
 	response.JSON(200, task.ToDTO())
 }
+
+// @Summary List task logs
+// @Description List the ffmpeg and pre/post-processing output of a task
+// @Tags tasks
+// @Param uuid path string true "the tasks uuid"
+// @Param page query int false "the page of a pagination request (min 0)"
+// @Param perPage query int false "the amount of results of a pagination request (min 1; max: 100)"
+// @Produce json
+// @Success 200 {object} []dto.TaskLog
+// @Router /tasks/{uuid}/logs [get]
+func (c *Controller) logs(response *goyave.Response, request *goyave.Request) {
+	query := typeutil.MustConvert[*dto.Pagination](request.Query)
+	uuid := request.RouteParams["uuid"]
+
+	taskLogs, total, err := c.taskService.Logs(uuid, query.Page.Default(0), query.PerPage.Default(100))
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/tasks#task-logs"))
+		return
+	}
+
+	response.Header().Set("X-Total", fmt.Sprintf("%d", total))
+
+	// Transform each log line to its DTO
+	var taskLogDTOs = []dto.TaskLog{}
+	for _, taskLog := range *taskLogs {
+		taskLogDTOs = append(taskLogDTOs, *taskLog.ToDTO())
+	}
+
+	response.JSON(200, taskLogDTOs)
+}
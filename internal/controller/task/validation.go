@@ -1,8 +1,12 @@
 package task
 
 import (
+	"regexp"
+
+	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/validate"
 	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/typeutil"
 	v "goyave.dev/goyave/v5/validation"
 )
 
@@ -14,7 +18,7 @@ func (c *Controller) NewTaskRequest(_ *goyave.Request) v.RuleSet {
 		{Path: "command", Rules: v.List{
 			v.String(),
 			v.WithMessage(v.RequiredIf(func(ctx *v.Context) bool {
-				data, ok := ctx.Data.(map[string]any)
+				data, ok := ctx.Parent.(map[string]any)
 				if !ok {
 					return false
 				}
@@ -28,7 +32,7 @@ func (c *Controller) NewTaskRequest(_ *goyave.Request) v.RuleSet {
 		{Path: "preset", Rules: v.List{
 			v.String(),
 			v.WithMessage(v.RequiredIf(func(ctx *v.Context) bool {
-				data, ok := ctx.Data.(map[string]any)
+				data, ok := ctx.Parent.(map[string]any)
 				if !ok {
 					return false
 				}
@@ -40,6 +44,17 @@ func (c *Controller) NewTaskRequest(_ *goyave.Request) v.RuleSet {
 		}},
 
 		{Path: "priority", Rules: v.List{v.Uint()}},
+		{Path: "weight", Rules: v.List{v.Uint()}},
+		{Path: "scheduledAt", Rules: v.List{v.Int64(), v.Min(0)}},
+
+		{Path: "dependsOn", Rules: v.List{v.Array()}},
+		{Path: "dependsOn[]", Rules: v.List{v.String(), v.Required()}},
+
+		{Path: "labels", Rules: v.List{v.Array()}},
+		{Path: "labels[]", Rules: v.List{v.String(), v.Required()}},
+
+		{Path: "tags", Rules: v.List{v.Array()}},
+		{Path: "tags[]", Rules: v.List{v.String(), v.Required()}},
 
 		{Path: "inputFile", Rules: v.List{v.String()}},
 		{Path: "outputFile", Rules: v.List{v.String()}},
@@ -49,6 +64,13 @@ func (c *Controller) NewTaskRequest(_ *goyave.Request) v.RuleSet {
 		{Path: "webhooks", Rules: v.List{v.Array()}},
 		{Path: "webhooks[].url", Rules: v.List{validate.PreserveValue(v.URL()), v.Required()}},
 		{Path: "webhooks[].event", Rules: v.List{v.String(), v.Required()}},
+		{Path: "webhooks[].headers", Rules: v.List{v.Object()}},
+		{Path: "webhooks[].headers.*", Rules: v.List{v.String()}},
+		{Path: "webhooks[].secret", Rules: v.List{v.String()}},
+		{Path: "webhooks[].template", Rules: v.List{v.String(), validate.Template()}},
+		{Path: "webhooks[].contentType", Rules: v.List{v.String()}},
+		{Path: "webhooks[].timeout", Rules: v.List{v.Uint(), v.Between(1, 120)}},
+		{Path: "webhooks[].maxAttempts", Rules: v.List{v.Uint(), v.Between(1, 50)}},
 
 		{Path: "preProcessing", Rules: v.List{v.Object()}},
 		{Path: "preProcessing.scriptPath", Rules: v.List{v.String()}},
@@ -58,5 +80,100 @@ func (c *Controller) NewTaskRequest(_ *goyave.Request) v.RuleSet {
 		{Path: "postProcessing", Rules: v.List{v.Object()}},
 		{Path: "postProcessing.scriptPath", Rules: v.List{v.String()}},
 		{Path: "postProcessing.sidecarPath", Rules: v.List{v.String()}},
+
+		{Path: "retry", Rules: v.List{v.Object()}},
+		{Path: "retry.maxAttempts", Rules: v.List{v.Uint(), v.Required(), v.Max(100)}},
+		{Path: "retry.delay", Rules: v.List{v.Uint(), v.Max(86400)}},
+		{Path: "retry.backoff", Rules: v.List{v.Float64(), v.Min(1)}},
+		{Path: "retry.match", Rules: v.List{v.String(), validate.Regexp()}},
+	}
+}
+
+// UpdateTaskRequest reuses the rules of a new task; all fields are optional and the preset, dependencies and labels can not be changed
+func (c *Controller) UpdateTaskRequest(r *goyave.Request) v.RuleSet {
+	rules := v.RuleSet{}
+	for _, rule := range c.NewTaskRequest(r) {
+		switch rule.Path {
+		case "preset", "dependsOn", "dependsOn[]", "labels", "labels[]":
+		case "name", "command":
+			rules = append(rules, &v.FieldRules{Path: rule.Path, Rules: v.List{v.String()}})
+		default:
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (c *Controller) NewWorkflowRequest(r *goyave.Request) v.RuleSet {
+	return v.RuleSet{
+		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
+		{Path: "tasks", Rules: v.List{v.Array(), v.Required(), v.Min(1)}},
+		{Path: "tasks[]", Rules: c.NewTaskRequest(r)},
+		{Path: "tasks[].id", Rules: v.List{v.String(), v.Required()}},
+	}
+}
+
+func (c *Controller) CancelTaskRequest(_ *goyave.Request) v.RuleSet {
+	return v.RuleSet{
+		{Path: "cleanup", Rules: v.List{v.Bool()}},
 	}
 }
+
+var (
+	taskStatuses = []string{string(dto.Queued), string(dto.Running), string(dto.PreProcessing), string(dto.PostProcessing), string(dto.DoneSuccessful), string(dto.DoneError), string(dto.DoneCanceled), string(dto.Paused)}
+	taskSources  = []string{string(dto.API), string(dto.WATCHFOLDER), string(dto.SCHEDULE)}
+)
+
+func (c *Controller) TaskFilterRequest(r *goyave.Request) v.RuleSet {
+	return append(validate.PaginationRequest(r), taskFilterRules("")...)
+}
+
+// taskFilterRules validates the fields of a task filter, prefix is prepended to their paths
+func taskFilterRules(prefix string) v.RuleSet {
+	return v.RuleSet{
+		{Path: prefix + "status", Rules: v.List{v.Array()}},
+		{Path: prefix + "status[]", Rules: v.List{v.String(), v.In(taskStatuses)}},
+		{Path: prefix + "source", Rules: v.List{v.Array()}},
+		{Path: prefix + "source[]", Rules: v.List{v.String(), v.In(taskSources)}},
+		{Path: prefix + "metadata", Rules: v.List{v.Array()}},
+		{Path: prefix + "metadata[]", Rules: v.List{v.String(), v.Regex(regexp.MustCompile(`^[\w-]+(\.[\w-]+)*:`))}},
+		{Path: prefix + "batch", Rules: v.List{v.String()}},
+		{Path: prefix + "preset", Rules: v.List{v.String()}},
+		{Path: prefix + "client", Rules: v.List{v.String()}},
+		{Path: prefix + "watchfolder", Rules: v.List{v.String()}},
+		{Path: prefix + "search", Rules: v.List{v.String()}},
+		{Path: prefix + "sort", Rules: v.List{v.String(), v.In([]string{"createdAt", "updatedAt", "startedAt", "finishedAt", "priority", "progress", "name", "status"})}},
+		{Path: prefix + "order", Rules: v.List{v.String(), v.In([]string{"asc", "desc"})}},
+		{Path: prefix + "createdFrom", Rules: v.List{v.Int64(), v.Min(0)}},
+		{Path: prefix + "createdTo", Rules: v.List{v.Int64(), v.Min(0)}},
+		{Path: prefix + "finishedFrom", Rules: v.List{v.Int64(), v.Min(0)}},
+		{Path: prefix + "finishedTo", Rules: v.List{v.Int64(), v.Min(0)}},
+	}
+}
+
+func (c *Controller) BulkOperationRequest(_ *goyave.Request) v.RuleSet {
+	return append(v.RuleSet{
+		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
+		{Path: "action", Rules: v.List{v.String(), v.Required(), v.In([]string{string(dto.BulkCancel), string(dto.BulkRestart), string(dto.BulkDelete), string(dto.BulkPriority)})}},
+		{Path: "priority", Rules: v.List{v.Uint(), v.RequiredIf(func(ctx *v.Context) bool {
+			data, ok := ctx.Parent.(map[string]any)
+			return ok && data["action"] == string(dto.BulkPriority)
+		})}},
+		{Path: "cleanup", Rules: v.List{v.Bool()}},
+
+		{Path: "uuids", Rules: v.List{
+			v.Array(),
+			v.WithMessage(v.RequiredIf(func(ctx *v.Context) bool {
+				data, ok := ctx.Parent.(map[string]any)
+				if !ok || data["filter"] == nil {
+					return false
+				}
+				filter, err := typeutil.Convert[*dto.TaskFilter](data["filter"])
+				return err == nil && !filter.HasCriteria()
+			}), "Either uuids or a filter with at least one criterion must be set"),
+		}},
+		{Path: "uuids[]", Rules: v.List{v.String(), v.Required()}},
+
+		{Path: "filter", Rules: v.List{v.Object()}},
+	}, taskFilterRules("filter.")...)
+}
@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/validate"
 	"goyave.dev/goyave/v5"
 	v "goyave.dev/goyave/v5/validation"
@@ -11,5 +12,32 @@ func (c *Controller) NewWebhookRequest(_ *goyave.Request) v.RuleSet {
 		{Path: v.CurrentElement, Rules: v.List{v.Object()}},
 		{Path: "event", Rules: v.List{v.String(), v.Required()}},
 		{Path: "url", Rules: v.List{validate.PreserveValue(v.URL()), v.Required()}},
+		{Path: "headers", Rules: v.List{v.Object()}},
+		{Path: "headers.*", Rules: v.List{v.String()}},
+		{Path: "secret", Rules: v.List{v.String()}},
+		{Path: "template", Rules: v.List{v.String(), validate.Template()}},
+		{Path: "contentType", Rules: v.List{v.String()}},
+		{Path: "timeout", Rules: v.List{v.Uint(), v.Between(1, 120)}},
+		{Path: "maxAttempts", Rules: v.List{v.Uint(), v.Between(1, 50)}},
 	}
 }
+
+func (c *Controller) WebhookDeliveryFilterRequest(r *goyave.Request) v.RuleSet {
+	return append(validate.PaginationRequest(r), v.RuleSet{
+		{Path: "status", Rules: v.List{v.Array()}},
+		{Path: "status[]", Rules: v.List{v.String(), v.In([]string{string(dto.DeliveryPending), string(dto.DeliveryDelivering), string(dto.DeliveryDeadLetter)})}},
+	}...)
+}
+
+func (c *Controller) WebhookExecutionFilterRequest(r *goyave.Request) v.RuleSet {
+	return append(validate.PaginationRequest(r), v.RuleSet{
+		{Path: "event", Rules: v.List{v.Array()}},
+		{Path: "event[]", Rules: v.List{v.String()}},
+		{Path: "webhook", Rules: v.List{v.String()}},
+		{Path: "task", Rules: v.List{v.String()}},
+		{Path: "statusFrom", Rules: v.List{v.Int(), v.Between(100, 599)}},
+		{Path: "statusTo", Rules: v.List{v.Int(), v.Between(100, 599)}},
+		{Path: "createdFrom", Rules: v.List{v.Int64(), v.Min(0)}},
+		{Path: "createdTo", Rules: v.List{v.Int64(), v.Min(0)}},
+	}...)
+}
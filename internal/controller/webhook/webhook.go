@@ -15,11 +15,15 @@ import (
 
 type Service interface {
 	List(page int, perPage int) (*[]model.Webhook, int64, error)
-	ListExecutions(page int, perPage int) (*[]model.WebhookExecution, int64, error)
+	ListExecutions(page int, perPage int, filter *dto.WebhookExecutionFilter) (*[]model.WebhookExecution, int64, error)
+	ListDeliveries(page int, perPage int, status []dto.WebhookDeliveryStatus) (*[]model.WebhookDelivery, int64, error)
 	Add(newWebhook *dto.NewWebhook) (*model.Webhook, error)
 	Delete(uuid string) error
 	Get(uuid string) (*model.Webhook, error)
 	Update(uuid string, newWebhook *dto.NewWebhook) (*model.Webhook, error)
+	Redeliver(uuid string) (*model.WebhookExecution, error)
+	Test(uuid string) (*model.WebhookExecution, error)
+	Stats(uuid string) (*dto.WebhookStats, error)
 }
 
 type Controller struct {
@@ -38,7 +42,11 @@ func (c *Controller) RegisterRoutes(router *goyave.Router) {
 	router.Post("/webhooks", c.add).ValidateBody(c.NewWebhookRequest)
 	router.Put("/webhooks/{uuid}", c.update).ValidateBody(c.NewWebhookRequest)
 	router.Get("/webhooks", c.list).ValidateQuery(validate.PaginationRequest)
-	router.Get("/webhooks/executions", c.listExecutions).ValidateQuery(validate.PaginationRequest)
+	router.Get("/webhooks/executions", c.listExecutions).ValidateQuery(c.WebhookExecutionFilterRequest)
+	router.Get("/webhooks/deliveries", c.listDeliveries).ValidateQuery(c.WebhookDeliveryFilterRequest)
+	router.Post("/webhooks/executions/{uuid}/redeliver", c.redeliver)
+	router.Post("/webhooks/{uuid}/test", c.test)
+	router.Get("/webhooks/{uuid}/stats", c.stats)
 	router.Get("/webhooks/{uuid}", c.get)
 }
 
@@ -88,14 +96,21 @@ func (c *Controller) list(response *goyave.Response, request *goyave.Request) {
 }
 
 // @Summary List all webhooks executions
-// @Description List all existing webhook executions
+// @Description List the existing webhook executions, newest first, optionally filtered
 // @Tags webhooks
+// @Param webhook query string false "filter by the uuid of the global webhook"
+// @Param task query string false "filter by the uuid of the task the event was fired for"
+// @Param event query []string false "filter by event"
+// @Param statusFrom query int false "minimum response status (inclusive)"
+// @Param statusTo query int false "maximum response status (inclusive)"
+// @Param createdFrom query int false "created at or after (unix milliseconds)"
+// @Param createdTo query int false "created at or before (unix milliseconds)"
 // @Produce json
 // @Success 200 {object} []dto.WebhookExecution
 // @Router /webhooks/executions [get]
 func (c *Controller) listExecutions(response *goyave.Response, request *goyave.Request) {
-	query := typeutil.MustConvert[*dto.Pagination](request.Query)
-	webhookExecutions, total, err := c.webhookService.ListExecutions(query.Page.Default(0), query.PerPage.Default(100))
+	query := typeutil.MustConvert[*dto.WebhookExecutionFilter](request.Query)
+	webhookExecutions, total, err := c.webhookService.ListExecutions(query.Page.Default(0), query.PerPage.Default(100), query)
 	if err != nil {
 		response.JSON(400, exception.HTTPBadRequest(err, ""))
 		return
@@ -112,8 +127,87 @@ func (c *Controller) listExecutions(response *goyave.Response, request *goyave.R
 	response.JSON(200, webhooksExecutionDTOs)
 }
 
+// @Summary List queued webhook deliveries
+// @Description List the pending and dead-lettered webhook deliveries; delivered webhooks are removed from the queue
+// @Tags webhooks
+// @Param status query []string false "filter by status (PENDING, DELIVERING, DEAD_LETTER)"
+// @Produce json
+// @Success 200 {object} []dto.WebhookDelivery
+// @Router /webhooks/deliveries [get]
+func (c *Controller) listDeliveries(response *goyave.Response, request *goyave.Request) {
+	query := typeutil.MustConvert[*dto.WebhookDeliveryFilter](request.Query)
+	deliveries, total, err := c.webhookService.ListDeliveries(query.Page.Default(0), query.PerPage.Default(100), query.Status)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/webhooks#delivery-queue"))
+		return
+	}
+
+	response.Header().Set("X-Total", fmt.Sprintf("%d", total))
+
+	var deliveryDTOs = []dto.WebhookDelivery{}
+	for _, delivery := range *deliveries {
+		deliveryDTOs = append(deliveryDTOs, *delivery.ToDTO())
+	}
+
+	response.JSON(200, deliveryDTOs)
+}
+
+// @Summary Redeliver a webhook execution
+// @Description Resend the recorded payload of a webhook execution once and record the result as a new execution
+// @Tags webhooks
+// @Param uuid path string true "the webhook executions uuid"
+// @Produce json
+// @Success 200 {object} dto.WebhookExecution
+// @Router /webhooks/executions/{uuid}/redeliver [post]
+func (c *Controller) redeliver(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+	execution, err := c.webhookService.Redeliver(uuid)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/webhooks#redelivering-a-webhook"))
+		return
+	}
+
+	response.JSON(200, execution.ToDTO())
+}
+
+// @Summary Test a webhook
+// @Description Send a synthetic event with sample data to the webhook once and record the result as a new execution
+// @Tags webhooks
+// @Param uuid path string true "the webhooks uuid"
+// @Produce json
+// @Success 200 {object} dto.WebhookExecution
+// @Router /webhooks/{uuid}/test [post]
+func (c *Controller) test(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+	execution, err := c.webhookService.Test(uuid)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/webhooks#testing-a-webhook"))
+		return
+	}
+
+	response.JSON(200, execution.ToDTO())
+}
+
+// @Summary Get webhook statistics
+// @Description Get the success rate, the p50/p95 latency and the last failure of a webhook's recorded executions
+// @Tags webhooks
+// @Param uuid path string true "the webhooks uuid"
+// @Produce json
+// @Success 200 {object} dto.WebhookStats
+// @Router /webhooks/{uuid}/stats [get]
+func (c *Controller) stats(response *goyave.Response, request *goyave.Request) {
+	uuid := request.RouteParams["uuid"]
+	stats, err := c.webhookService.Stats(uuid)
+	if err != nil {
+		response.JSON(400, exception.HTTPBadRequest(err, "https://docs.ffmate.io/docs/webhooks#webhook-statistics"))
+		return
+	}
+
+	response.JSON(200, stats)
+}
+
 // @Summary Add a new webhook
-// @Description Add a new webhook for an event
+// @Description Add a new webhook for an event; payloads are signed using HMAC-SHA256 if a secret is set
 // @Tags webhooks
 // @Accept json
 // @Param request body dto.NewWebhook true "new webhook"
@@ -152,7 +246,7 @@ func (c *Controller) get(response *goyave.Response, request *goyave.Request) {
 }
 
 // @Summary Update a webhook
-// @Description Update a webhook for an event
+// @Description Update a webhook for an event; the current secret is kept if none is given
 // @Tags webhooks
 // @Accept json
 // @Param request body dto.NewWebhook true "updated webhook"
@@ -0,0 +1,45 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestNext(t *testing.T) {
+	from := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC) // wednesday
+
+	tests := map[string]time.Time{
+		"* * * * *":        time.Date(2025, time.January, 15, 10, 31, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC),
+		"0 2 * * *":        time.Date(2025, time.January, 16, 2, 0, 0, 0, time.UTC),
+		"@daily":           time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC),
+		"@hourly":          time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC),
+		"0 22 * * mon-fri": time.Date(2025, time.January, 15, 22, 0, 0, 0, time.UTC),
+		"0 0 * * 7":        time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 1 feb *":      time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":       time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * 1":        time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC), // day-of-month OR day-of-week
+		"5/20 1-3 * * *":   time.Date(2025, time.January, 16, 1, 5, 0, 0, time.UTC),
+	}
+
+	for expr, expected := range tests {
+		s, err := Parse(expr)
+		require.NoError(t, err, expr)
+		require.Equal(t, expected, s.Next(from), expr)
+	}
+}
+
+func TestNextNone(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, s.Next(time.Now()).IsZero())
+}
@@ -16,6 +16,11 @@ type Client struct {
 	Version string
 	FFMpeg  string
 
+	Labels []string `gorm:"serializer:json"`
+
+	Queue   dto.QueueStatus
+	Offline bool
+
 	LastSeen int64
 }
 
@@ -28,6 +33,9 @@ func (c *Client) ToDTO() *dto.Client {
 		Arch:       c.Arch,
 		Version:    c.Version,
 		FFMpeg:     c.FFMpeg,
+		Labels:     c.Labels,
+		Queue:      c.Queue,
+		Offline:    c.Offline,
 		LastSeen:   c.LastSeen,
 	}
 
@@ -13,6 +13,10 @@ type Preset struct {
 	Webhooks       *dto.DirectWebhooks       `gorm:"type:jsonb"`
 	PostProcessing *dto.NewPrePostProcessing `gorm:"type:jsonb"`
 	PreProcessing  *dto.NewPrePostProcessing `gorm:"type:jsonb"`
+	Retry          *dto.RetryPolicy          `gorm:"type:jsonb"`
+	Rules          *dto.PresetRules          `gorm:"type:jsonb"`
+	Labels         []string                  `gorm:"serializer:json"`
+	Tags           []string                  `gorm:"serializer:json"`
 	DeletedAt      gorm.DeletedAt            `gorm:"index"`
 	Name           string
 	OutputFile     string
@@ -20,6 +24,8 @@ type Preset struct {
 	UUID           string
 	Description    string
 	Priority       uint
+	Weight         uint
+	MaxConcurrent  uint
 	ID             uint `gorm:"primarykey"`
 }
 
@@ -35,7 +41,16 @@ func (m *Preset) ToDTO() *dto.Preset {
 
 		Priority: m.Priority,
 
-		Webhooks: m.Webhooks,
+		Webhooks: m.Webhooks.Redacted(),
+
+		Retry: m.Retry,
+		Rules: m.Rules,
+
+		Labels: m.Labels,
+
+		Weight:        m.Weight,
+		Tags:          m.Tags,
+		MaxConcurrent: m.MaxConcurrent,
 
 		PreProcessing:  m.PreProcessing,
 		PostProcessing: m.PostProcessing,
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"gorm.io/gorm"
+)
+
+type Schedule struct {
+	Metadata    *dto.MetadataMap `gorm:"serializer:json"`
+	DeletedAt   gorm.DeletedAt   `gorm:"index"`
+	Error       string
+	Preset      string
+	UUID        string
+	Name        string
+	Description string
+	Cron        string
+	InputFile   string
+	OutputFile  string
+	Priority    *uint
+	ID          uint  `gorm:"primarykey"`
+	UpdatedAt   int64 `gorm:"autoUpdateTime:milli"`
+	CreatedAt   int64 `gorm:"autoCreateTime:milli"`
+	LastRun     int64
+	NextRun     int64 `gorm:"index"`
+	Suspended   bool
+}
+
+func (m *Schedule) ToDTO() *dto.Schedule {
+	return &dto.Schedule{
+		UUID: m.UUID,
+
+		Name:        m.Name,
+		Description: m.Description,
+
+		Cron: m.Cron,
+
+		Preset:     m.Preset,
+		InputFile:  m.InputFile,
+		OutputFile: m.OutputFile,
+		Metadata:   m.Metadata,
+		Priority:   m.Priority,
+
+		Suspended: m.Suspended,
+
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+
+		Error:   m.Error,
+		LastRun: m.LastRun,
+		NextRun: m.NextRun,
+	}
+}
+
+func (Schedule) TableName() string {
+	return "schedule"
+}
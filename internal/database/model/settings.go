@@ -5,11 +5,20 @@ import (
 )
 
 type Settings struct {
-	ID uint `gorm:"primaryKey;unique"`
+	Retention          *dto.Retention `gorm:"type:jsonb"`
+	MaxConcurrentTasks *uint
+	DefaultPriority    *uint
+	Nodes              []dto.NodeSettings `gorm:"serializer:json"`
+	ID                 uint               `gorm:"primaryKey;unique"`
 }
 
 func (r *Settings) ToDTO() *dto.Settings {
-	return &dto.Settings{}
+	return &dto.Settings{
+		MaxConcurrentTasks: r.MaxConcurrentTasks,
+		DefaultPriority:    r.DefaultPriority,
+		Retention:          r.Retention,
+		Nodes:              r.Nodes,
+	}
 }
 
 func (Settings) TableName() string {
@@ -14,6 +14,12 @@ type Task struct {
 	Metadata         *dto.MetadataMap       `gorm:"serializer:json"`
 	Command          *dto.RawResolved       `gorm:"type:jsonb"`
 	InputFile        *dto.RawResolved       `gorm:"type:jsonb"`
+	Retry            *dto.RetryPolicy       `gorm:"type:jsonb"`
+	Attempts         *dto.TaskAttempts      `gorm:"type:jsonb"`
+	Probe            *dto.Probe             `gorm:"type:jsonb"`
+	Dependencies     []TaskDependency       `gorm:"foreignKey:Task;references:UUID;constraint:-"`
+	Labels           []TaskLabel            `gorm:"foreignKey:Task;references:UUID;constraint:-"`
+	Tags             []string               `gorm:"serializer:json"`
 	DeletedAt        gorm.DeletedAt         `gorm:"index"`
 	Name             string
 	Source           dto.TaskSource
@@ -21,8 +27,10 @@ type Task struct {
 	Error            string
 	ClientIdentifier string `gorm:"index"`
 	UUID             string
-	Batch            string
+	Batch            string `gorm:"index"`
+	Preset           string
 	Priority         uint
+	Weight           uint `gorm:"default:1"`
 	Remaining        float64
 	UpdatedAt        int64 `gorm:"autoUpdateTime:milli"`
 	ID               uint  `gorm:"primarykey"`
@@ -30,14 +38,18 @@ type Task struct {
 	CreatedAt        int64 `gorm:"autoCreateTime:milli"`
 	StartedAt        int64
 	FinishedAt       int64
+	Attempt          uint
+	RetryAt          int64 `gorm:"default:0"`
+	ScheduledAt      int64 `gorm:"default:0"`
 }
 
 func (m *Task) ToDTO() *dto.Task {
 	d := &dto.Task{
 		UUID: m.UUID,
 
-		Name:  m.Name,
-		Batch: m.Batch,
+		Name:   m.Name,
+		Batch:  m.Batch,
+		Preset: m.Preset,
 
 		Command:    m.Command,
 		InputFile:  m.InputFile,
@@ -54,8 +66,18 @@ func (m *Task) ToDTO() *dto.Task {
 		Source: m.Source,
 
 		Priority: m.Priority,
+		Weight:   m.Weight,
+		Tags:     m.Tags,
 
-		Webhooks: m.Webhooks,
+		Webhooks: m.Webhooks.Redacted(),
+
+		Retry:    m.Retry,
+		Attempt:  m.Attempt,
+		Attempts: m.Attempts,
+		Probe:    m.Probe,
+		RetryAt:  m.RetryAt,
+
+		ScheduledAt: m.ScheduledAt,
 
 		PreProcessing:  m.PreProcessing,
 		PostProcessing: m.PostProcessing,
@@ -67,6 +89,20 @@ func (m *Task) ToDTO() *dto.Task {
 		UpdatedAt: m.UpdatedAt,
 	}
 
+	if len(m.Dependencies) > 0 {
+		d.DependsOn = make([]string, 0, len(m.Dependencies))
+		for _, dependency := range m.Dependencies {
+			d.DependsOn = append(d.DependsOn, dependency.DependsOn)
+		}
+	}
+
+	if len(m.Labels) > 0 {
+		d.Labels = make([]string, 0, len(m.Labels))
+		for _, label := range m.Labels {
+			d.Labels = append(d.Labels, label.Label)
+		}
+	}
+
 	if m.Client != nil {
 		d.Client = &dto.Client{
 			Identifier: m.Client.Identifier,
@@ -0,0 +1,11 @@
+package model
+
+type TaskDependency struct {
+	Task      string `gorm:"index"`
+	DependsOn string `gorm:"index"`
+	ID        uint   `gorm:"primarykey"`
+}
+
+func (TaskDependency) TableName() string {
+	return "taskDependency"
+}
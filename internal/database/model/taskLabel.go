@@ -0,0 +1,11 @@
+package model
+
+type TaskLabel struct {
+	Task  string `gorm:"index"`
+	Label string `gorm:"index"`
+	ID    uint   `gorm:"primarykey"`
+}
+
+func (TaskLabel) TableName() string {
+	return "taskLabel"
+}
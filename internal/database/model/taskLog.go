@@ -0,0 +1,28 @@
+package model
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+type TaskLog struct {
+	Task      string `gorm:"index"`
+	Source    dto.TaskLogSource
+	Line      string
+	ID        uint  `gorm:"primarykey"`
+	CreatedAt int64 `gorm:"autoCreateTime:milli"`
+}
+
+func (m *TaskLog) ToDTO() *dto.TaskLog {
+	return &dto.TaskLog{
+		Task: m.Task,
+
+		Source: m.Source,
+		Line:   m.Line,
+
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func (TaskLog) TableName() string {
+	return "taskLog"
+}
@@ -8,19 +8,32 @@ import (
 )
 
 type Webhook struct {
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
-	UUID      string
-	Event     dto.WebhookEvent
-	URL       string
-	ID        uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt    `gorm:"index"`
+	Headers     map[string]string `gorm:"serializer:json"`
+	UUID        string
+	Secret      string
+	Template    string
+	ContentType string
+	Timeout     uint
+	MaxAttempts uint
+	Event       dto.WebhookEvent
+	URL         string
+	ID          uint `gorm:"primarykey"`
 }
 
 func (m *Webhook) ToDTO() *dto.Webhook {
 	return &dto.Webhook{
-		Event: m.Event,
-		URL:   m.URL,
+		Event:   m.Event,
+		URL:     m.URL,
+		Headers: dto.MaskHeaders(m.Headers),
+		Signed:  m.Secret != "",
+
+		Template:    m.Template,
+		ContentType: m.ContentType,
+		Timeout:     m.Timeout,
+		MaxAttempts: m.MaxAttempts,
 
 		UUID: m.UUID,
 
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+type WebhookDelivery struct {
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Headers        map[string]string `gorm:"serializer:json"`
+	UUID           string            `gorm:"uniqueIndex"`
+	Webhook        string
+	Task           string
+	Event          dto.WebhookEvent
+	URL            string
+	Body           string
+	ContentType    string
+	Secret         string
+	Status         dto.WebhookDeliveryStatus `gorm:"index"`
+	Error          string
+	ClaimedBy      string
+	NextAttemptAt  int64 `gorm:"index"`
+	ClaimedAt      int64
+	ResponseStatus int
+	Attempts       uint
+	MaxAttempts    uint
+	Timeout        uint
+	ID             uint `gorm:"primarykey"`
+}
+
+func (m *WebhookDelivery) ToDTO() *dto.WebhookDelivery {
+	return &dto.WebhookDelivery{
+		UUID:    m.UUID,
+		Webhook: m.Webhook,
+		Task:    m.Task,
+
+		Event:       m.Event,
+		URL:         m.URL,
+		Headers:     dto.MaskHeaders(m.Headers),
+		Body:        m.Body,
+		ContentType: m.ContentType,
+
+		Status:         m.Status,
+		Error:          m.Error,
+		ResponseStatus: m.ResponseStatus,
+
+		Attempts:      m.Attempts,
+		MaxAttempts:   m.MaxAttempts,
+		NextAttemptAt: m.NextAttemptAt,
+		Timeout:       m.Timeout,
+
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhookDelivery"
+}
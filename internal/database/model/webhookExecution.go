@@ -8,26 +8,39 @@ import (
 )
 
 type WebhookExecution struct {
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Request   *dto.WebhookRequest  `gorm:"json"`
-	Response  *dto.WebhookResponse `gorm:"json"`
-	DeletedAt gorm.DeletedAt       `gorm:"index"`
-	UUID      string
-	Event     dto.WebhookEvent
-	URL       string
-	ID        uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Request        *dto.WebhookRequest  `gorm:"json"`
+	Response       *dto.WebhookResponse `gorm:"json"`
+	DeletedAt      gorm.DeletedAt       `gorm:"index"`
+	UUID           string
+	Webhook        string `gorm:"index"`
+	Task           string `gorm:"index"`
+	Delivery       string
+	Event          dto.WebhookEvent
+	URL            string
+	Error          string
+	ResponseStatus int
+	Duration       int64
+	Attempt        uint
+	ID             uint `gorm:"primarykey"`
 }
 
 func (m *WebhookExecution) ToDTO() *dto.WebhookExecution {
 	return &dto.WebhookExecution{
-		UUID: m.UUID,
+		UUID:     m.UUID,
+		Webhook:  m.Webhook,
+		Task:     m.Task,
+		Delivery: m.Delivery,
+		Attempt:  m.Attempt,
+		Duration: m.Duration,
 
 		Event: m.Event,
 		URL:   m.URL,
 
 		Request:  m.Request,
 		Response: m.Response,
+		Error:    m.Error,
 
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
@@ -30,6 +30,31 @@ func (r *Client) Add(newClient *model.Client) (*model.Client, error) {
 	return newClient, db.Error
 }
 
+func (r *Client) FirstByIdentifier(identifier string) (*model.Client, error) {
+	var client model.Client
+	result := r.DB.Where("identifier = ?", identifier).First(&client)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &client, nil
+}
+
+// ListStale returns all online clients that were not seen since the given time
+func (r *Client) ListStale(lastSeen int64) (*[]model.Client, error) {
+	var clients = &[]model.Client{}
+	db := r.DB.Where("offline = ? AND last_seen < ?", false, lastSeen).Find(clients)
+	return clients, db.Error
+}
+
+// MarkOffline marks the client as offline and reports whether it was online before
+func (r *Client) MarkOffline(identifier string) (bool, error) {
+	db := r.DB.Model(&model.Client{}).Where("identifier = ? AND offline = ?", identifier, false).Update("offline", true)
+	return db.RowsAffected == 1, db.Error
+}
+
 func (r *Client) First() (*model.Client, error) {
 	var client model.Client
 	result := r.DB.First(&client)
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"gorm.io/gorm"
+)
+
+type Janitor struct {
+	DB *gorm.DB
+}
+
+// transaction runs fn in a transaction; a dry run only counts the affected rows and therefore needs no transaction
+func (r *Janitor) transaction(dryRun bool, fn func(tx *gorm.DB) error) error {
+	if dryRun {
+		return fn(r.DB)
+	}
+	return r.DB.Transaction(fn)
+}
+
+// purge hard deletes the rows of m matching db or only counts them on a dry run
+func purge(db *gorm.DB, m any, dryRun bool) (int64, error) {
+	if dryRun {
+		var count int64
+		err := db.Model(m).Count(&count).Error
+		return count, err
+	}
+	db = db.Delete(m)
+	return db.RowsAffected, db.Error
+}
+
+// purgeTasks hard deletes the tasks selected by the given uuid subquery including their logs, dependencies and labels
+func purgeTasks(tx *gorm.DB, uuids *gorm.DB, dryRun bool) (tasks int64, logs int64, err error) {
+	logs, err = purge(tx.Where("task IN (?)", uuids), &model.TaskLog{}, dryRun)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !dryRun {
+		if err := tx.Where("task IN (?)", uuids).Delete(&model.TaskDependency{}).Error; err != nil {
+			return 0, 0, err
+		}
+		if err := tx.Where("task IN (?)", uuids).Delete(&model.TaskLabel{}).Error; err != nil {
+			return 0, 0, err
+		}
+	}
+
+	tasks, err = purge(tx.Unscoped().Where("uuid IN (?)", uuids), &model.Task{}, dryRun)
+	return tasks, logs, err
+}
+
+// PurgeTasks hard deletes the tasks of the given status finished before the given time (unix milliseconds);
+// soft-deleted tasks are left to PurgeDeleted so that a dry run does not count them twice
+func (r *Janitor) PurgeTasks(status dto.TaskStatus, finishedBefore int64, dryRun bool) (tasks int64, logs int64, err error) {
+	err = r.transaction(dryRun, func(tx *gorm.DB) error {
+		uuids := tx.Model(&model.Task{}).Select("uuid").Where("status = ? AND finished_at > 0 AND finished_at < ?", status, finishedBefore)
+		tasks, logs, err = purgeTasks(tx, uuids, dryRun)
+		return err
+	})
+	return tasks, logs, err
+}
+
+// PurgeDeleted hard deletes all rows soft-deleted before the given time
+func (r *Janitor) PurgeDeleted(deletedBefore time.Time, dryRun bool) (rows int64, logs int64, err error) {
+	err = r.transaction(dryRun, func(tx *gorm.DB) error {
+		uuids := tx.Unscoped().Model(&model.Task{}).Select("uuid").Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore)
+		rows, logs, err = purgeTasks(tx, uuids, dryRun)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range []any{&model.Preset{}, &model.Watchfolder{}, &model.Webhook{}, &model.WebhookExecution{}, &model.Schedule{}} {
+			n, err := purge(tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore), m, dryRun)
+			if err != nil {
+				return err
+			}
+			rows += n
+		}
+		return nil
+	})
+	return rows, logs, err
+}
+
+// CapWebhookExecutions hard deletes all but the newest webhook executions
+func (r *Janitor) CapWebhookExecutions(keep int, dryRun bool) (rows int64, err error) {
+	err = r.transaction(dryRun, func(tx *gorm.DB) error {
+		newest := tx.Unscoped().Model(&model.WebhookExecution{}).Select("id").Order("created_at DESC, id DESC").Limit(keep)
+		rows, err = purge(tx.Unscoped().Where("id NOT IN (?)", newest), &model.WebhookExecution{}, dryRun)
+		return err
+	})
+	return rows, err
+}
+
+// PurgeDeadLetters hard deletes the webhook deliveries dead-lettered before the given time
+func (r *Janitor) PurgeDeadLetters(deadBefore time.Time, dryRun bool) (rows int64, err error) {
+	return purge(r.DB.Where("status = ? AND updated_at < ?", dto.DeliveryDeadLetter, deadBefore), &model.WebhookDelivery{}, dryRun)
+}
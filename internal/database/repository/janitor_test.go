@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/testsuite/testserver"
+	"gorm.io/gorm"
+)
+
+func prepareJanitor(t *testing.T) (*gorm.DB, *Janitor) {
+	server := testserver.New(t)
+	(&Preset{DB: server.DB()}).Setup()
+	(&Webhook{DB: server.DB()}).Setup()
+	(&WebhookExecution{DB: server.DB()}).Setup()
+	(&Watchfolder{DB: server.DB()}).Setup()
+	(&Schedule{DB: server.DB()}).Setup()
+	(&Task{DB: server.DB()}).Setup()
+	(&TaskLog{DB: server.DB()}).Setup()
+	return server.DB(), &Janitor{DB: server.DB()}
+}
+
+func TestJanitorPurgeTasks(t *testing.T) {
+	db, repo := prepareJanitor(t)
+	now := time.Now()
+
+	old := &model.Task{UUID: uuid.NewString(), Status: dto.DoneSuccessful, FinishedAt: now.Add(-48 * time.Hour).UnixMilli()}
+	recent := &model.Task{UUID: uuid.NewString(), Status: dto.DoneSuccessful, FinishedAt: now.UnixMilli()}
+	failed := &model.Task{UUID: uuid.NewString(), Status: dto.DoneError, FinishedAt: now.Add(-48 * time.Hour).UnixMilli()}
+	db.Create([]*model.Task{old, recent, failed})
+	db.Create([]*model.TaskLog{{Task: old.UUID, Line: "a"}, {Task: old.UUID, Line: "b"}, {Task: recent.UUID, Line: "c"}})
+
+	// dry run reports without deleting
+	tasks, logs, err := repo.PurgeTasks(dto.DoneSuccessful, now.Add(-24*time.Hour).UnixMilli(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tasks)
+	assert.Equal(t, int64(2), logs)
+	var count int64
+	db.Unscoped().Model(&model.Task{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	tasks, logs, err = repo.PurgeTasks(dto.DoneSuccessful, now.Add(-24*time.Hour).UnixMilli(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tasks)
+	assert.Equal(t, int64(2), logs)
+	db.Unscoped().Model(&model.Task{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+	db.Model(&model.TaskLog{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestJanitorDryRunSoftDeleted(t *testing.T) {
+	db, repo := prepareJanitor(t)
+	now := time.Now()
+
+	// a finished task that has been soft-deleted is only covered by the deleted rule
+	deleted := &model.Task{UUID: uuid.NewString(), Status: dto.DoneSuccessful, FinishedAt: now.Add(-48 * time.Hour).UnixMilli()}
+	db.Create(deleted)
+	db.Create(&model.TaskLog{Task: deleted.UUID, Line: "a"})
+	db.Delete(deleted)
+
+	dryTasks, dryTaskLogs, err := repo.PurgeTasks(dto.DoneSuccessful, now.Add(-24*time.Hour).UnixMilli(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), dryTasks)
+	assert.Equal(t, int64(0), dryTaskLogs)
+	dryRows, dryLogs, err := repo.PurgeDeleted(now.Add(time.Minute), true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dryRows)
+	assert.Equal(t, int64(1), dryLogs)
+
+	// the dry run reports what the run deletes
+	tasks, taskLogs, err := repo.PurgeTasks(dto.DoneSuccessful, now.Add(-24*time.Hour).UnixMilli(), false)
+	assert.NoError(t, err)
+	rows, logs, err := repo.PurgeDeleted(now.Add(time.Minute), false)
+	assert.NoError(t, err)
+	assert.Equal(t, dryTasks+dryRows, tasks+rows)
+	assert.Equal(t, dryTaskLogs+dryLogs, taskLogs+logs)
+}
+
+func TestJanitorPurgeDeleted(t *testing.T) {
+	db, repo := prepareJanitor(t)
+
+	deleted := &model.Task{UUID: uuid.NewString(), Status: dto.DoneSuccessful}
+	kept := &model.Task{UUID: uuid.NewString(), Status: dto.DoneSuccessful}
+	preset := &model.Preset{UUID: uuid.NewString()}
+	db.Create([]*model.Task{deleted, kept})
+	db.Create(preset)
+	db.Create(&model.TaskLog{Task: deleted.UUID, Line: "a"})
+	db.Delete(deleted)
+	db.Delete(preset)
+
+	rows, logs, err := repo.PurgeDeleted(time.Now().Add(time.Minute), false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+	assert.Equal(t, int64(1), logs)
+
+	var count int64
+	db.Unscoped().Model(&model.Task{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	rows, _, err = repo.PurgeDeleted(time.Now().Add(time.Minute), false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+}
+
+func TestJanitorCapWebhookExecutions(t *testing.T) {
+	db, repo := prepareJanitor(t)
+
+	for i := range 5 {
+		db.Create(&model.WebhookExecution{UUID: uuid.NewString(), CreatedAt: time.Now().Add(time.Duration(i) * time.Minute)})
+	}
+
+	rows, err := repo.CapWebhookExecutions(2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), rows)
+
+	rows, err = repo.CapWebhookExecutions(2, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), rows)
+
+	var executions []model.WebhookExecution
+	db.Order("created_at ASC").Find(&executions)
+	assert.Len(t, executions, 2)
+}
+
+func TestJanitorPurgeDeadLetters(t *testing.T) {
+	db, repo := prepareJanitor(t)
+	(&WebhookDelivery{DB: db}).Setup()
+
+	old := time.Now().Add(-48 * time.Hour)
+	db.Create([]*model.WebhookDelivery{
+		{UUID: uuid.NewString(), Status: dto.DeliveryDeadLetter, CreatedAt: old, UpdatedAt: old},
+		{UUID: uuid.NewString(), Status: dto.DeliveryDeadLetter},
+		{UUID: uuid.NewString(), Status: dto.DeliveryPending, CreatedAt: old, UpdatedAt: old},
+	})
+
+	rows, err := repo.PurgeDeadLetters(time.Now().Add(-24*time.Hour), true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	rows, err = repo.PurgeDeadLetters(time.Now().Add(-24*time.Hour), false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	var count int64
+	db.Model(&model.WebhookDelivery{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
@@ -58,6 +58,17 @@ func (r *Preset) Count() (int64, error) {
 	return count, db.Error
 }
 
+/**
+ * Processing related methods
+ */
+
+// ListLimited returns all presets limiting their concurrent tasks
+func (r *Preset) ListLimited() (*[]model.Preset, error) {
+	var presets = &[]model.Preset{}
+	db := r.DB.Where("max_concurrent > 0").Find(presets)
+	return presets, db.Error
+}
+
 /**
  * Stats (telemetry) related methods
  */
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5/database"
+)
+
+type Schedule struct {
+	DB *gorm.DB
+}
+
+func (r *Schedule) Setup() *Schedule {
+	_ = r.DB.AutoMigrate(&model.Schedule{})
+	return r
+}
+
+func (r *Schedule) First(uuid string) (*model.Schedule, error) {
+	var schedule model.Schedule
+	result := r.DB.Where("uuid = ?", uuid).First(&schedule)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &schedule, nil
+}
+
+func (r *Schedule) Delete(s *model.Schedule) error {
+	r.DB.Delete(s)
+	return r.DB.Error
+}
+
+func (r *Schedule) List(page int, perPage int) (*[]model.Schedule, int64, error) {
+	var schedules = &[]model.Schedule{}
+	tx := r.DB.Order("created_at DESC")
+	d := database.NewPaginator(tx, page+1, perPage, schedules)
+	err := d.Find()
+	return d.Records, d.Total, err
+}
+
+func (r *Schedule) Add(newSchedule *model.Schedule) (*model.Schedule, error) {
+	db := r.DB.Create(newSchedule)
+	return newSchedule, db.Error
+}
+
+func (r *Schedule) Update(schedule *model.Schedule) (*model.Schedule, error) {
+	db := r.DB.Save(schedule)
+	return schedule, db.Error
+}
+
+func (r *Schedule) Count() (int64, error) {
+	var count int64
+	db := r.DB.Model(&model.Schedule{}).Count(&count)
+	return count, db.Error
+}
+
+/**
+ * Processing related methods
+ */
+
+// ListDue returns all active schedules whose next run is at or before the given time
+func (r *Schedule) ListDue(now int64) (*[]model.Schedule, error) {
+	var schedules = &[]model.Schedule{}
+	db := r.DB.Where("suspended = ? AND next_run > 0 AND next_run <= ?", false, now).Find(schedules)
+	return schedules, db.Error
+}
+
+// Claim advances the schedule to its next run and reports whether the run was claimed by this client
+func (r *Schedule) Claim(schedule *model.Schedule, lastRun int64, nextRun int64) (bool, error) {
+	db := r.DB.Model(&model.Schedule{}).
+		Where("uuid = ? AND next_run = ?", schedule.UUID, schedule.NextRun).
+		Updates(map[string]any{"last_run": lastRun, "next_run": nextRun})
+	return db.RowsAffected == 1, db.Error
+}
+
+// UpdateError stores the error of the last run without touching any other column of the schedule
+func (r *Schedule) UpdateError(uuid string, message string) (*model.Schedule, error) {
+	db := r.DB.Model(&model.Schedule{}).Where("uuid = ?", uuid).Update("error", message)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	return r.First(uuid)
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/welovemedia/ffmate/v2/internal/database/model"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
@@ -15,13 +17,13 @@ type Task struct {
 }
 
 func (r *Task) Setup() *Task {
-	_ = r.DB.AutoMigrate(&model.Task{})
+	_ = r.DB.AutoMigrate(&model.Task{}, &model.TaskDependency{}, &model.TaskLabel{})
 	return r
 }
 
 func (r *Task) First(uuid string) (*model.Task, error) {
 	var task model.Task
-	result := r.DB.Preload("Client").Where("uuid = ?", uuid).First(&task)
+	result := r.DB.Preload("Client").Preload("Dependencies").Preload("Labels").Where("uuid = ?", uuid).First(&task)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -36,17 +38,116 @@ func (r *Task) Delete(w *model.Task) error {
 	return r.DB.Error
 }
 
-func (r *Task) List(page int, perPage int) (*[]model.Task, int64, error) {
+// taskSortColumns maps the sort options of a task listing to their columns
+var taskSortColumns = map[string]string{
+	"createdAt":  "created_at",
+	"updatedAt":  "updated_at",
+	"startedAt":  "started_at",
+	"finishedAt": "finished_at",
+	"priority":   "priority",
+	"progress":   "progress",
+	"name":       "name",
+	"status":     "status",
+}
+
+// filter applies the conditions of the given filter
+func (r *Task) filter(tx *gorm.DB, filter *dto.TaskFilter) *gorm.DB {
+	if len(filter.Status) > 0 {
+		tx = tx.Where("status IN ?", filter.Status)
+	}
+	if len(filter.Source) > 0 {
+		tx = tx.Where("source IN ?", filter.Source)
+	}
+	if filter.Batch != "" {
+		tx = tx.Where("batch = ?", filter.Batch)
+	}
+	if filter.Preset != "" {
+		tx = tx.Where("preset = ?", filter.Preset)
+	}
+	if filter.Client != "" {
+		tx = tx.Where("client_identifier = ?", filter.Client)
+	}
+	if filter.Search != "" {
+		search := "%" + strings.ToLower(filter.Search) + "%"
+		tx = tx.Where("(LOWER(name) LIKE ? OR LOWER("+r.rawInputFile()+") LIKE ?)", search, search)
+	}
+	if filter.Watchfolder != "" {
+		tx = r.filterMetadata(tx, "ffmate.watchfolder.uuid", filter.Watchfolder)
+	}
+	for _, m := range filter.Metadata {
+		key, value, _ := strings.Cut(m, ":")
+		tx = r.filterMetadata(tx, key, value)
+	}
+	if filter.CreatedFrom > 0 {
+		tx = tx.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if filter.CreatedTo > 0 {
+		tx = tx.Where("created_at <= ?", filter.CreatedTo)
+	}
+	if filter.FinishedFrom > 0 {
+		tx = tx.Where("finished_at >= ?", filter.FinishedFrom)
+	}
+	if filter.FinishedTo > 0 {
+		tx = tx.Where("finished_at > 0 AND finished_at <= ?", filter.FinishedTo)
+	}
+	return tx
+}
+
+// rawInputFile selects the raw input path from the serialized input file
+func (r *Task) rawInputFile() string {
+	if r.DB.Dialector.Name() == "postgres" {
+		return "CAST(input_file AS jsonb) ->> 'raw'"
+	}
+	return "json_extract(input_file, '$.raw')"
+}
+
+// filterMetadata matches the metadata value of the (dot separated) key
+func (r *Task) filterMetadata(tx *gorm.DB, key string, value string) *gorm.DB {
+	if r.DB.Dialector.Name() == "postgres" {
+		return tx.Where("CAST(metadata AS jsonb) #>> CAST(? AS text[]) = ?", "{"+strings.ReplaceAll(key, ".", ",")+"}", value)
+	}
+	return tx.Where("CAST(json_extract(metadata, ?) AS TEXT) = ?", "$."+key, value)
+}
+
+func (r *Task) List(page int, perPage int, filter *dto.TaskFilter) (*[]model.Task, int64, error) {
 	var tasks = &[]model.Task{}
-	tx := r.DB.Preload("Client").Order("created_at DESC")
+	if filter == nil {
+		filter = &dto.TaskFilter{}
+	}
+	tx := r.filter(r.DB.Preload("Client").Preload("Dependencies").Preload("Labels"), filter)
+
+	column, ok := taskSortColumns[filter.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	order := "DESC"
+	if strings.EqualFold(filter.Order, "asc") {
+		order = "ASC"
+	}
+	tx = tx.Order(column + " " + order).Order("id " + order)
+
 	d := database.NewPaginator(tx, page+1, perPage, tasks)
 	err := d.Find()
 	return d.Records, d.Total, err
 }
 
+// ListUUIDs returns the uuids of all tasks matching the filter, restricted to the given uuids if any
+func (r *Task) ListUUIDs(filter *dto.TaskFilter, uuids []string) ([]string, error) {
+	tx := r.DB.Model(&model.Task{}).Order("id ASC")
+	if filter != nil {
+		tx = r.filter(tx, filter)
+	}
+	if len(uuids) > 0 {
+		tx = tx.Where("uuid IN ?", uuids)
+	}
+	var result []string
+	err := tx.Pluck("uuid", &result).Error
+	return result, err
+}
+
 func (r *Task) ListByBatch(uuid string, page int, perPage int) (*[]model.Task, int64, error) {
 	var tasks = &[]model.Task{}
-	tx := r.DB.Preload("Client").Order("created_at DESC").Where("batch = ?", uuid)
+	tx := r.DB.Preload("Client").Preload("Dependencies").Preload("Labels").Order("created_at DESC").Where("batch = ?", uuid)
 	d := database.NewPaginator(tx, page+1, perPage, tasks)
 	err := d.Find()
 	return d.Records, d.Total, err
@@ -60,6 +161,31 @@ func (r *Task) Add(newTask *model.Task) (*model.Task, error) {
 	return r.First(newTask.UUID)
 }
 
+// AddAll creates all tasks in a single transaction
+func (r *Task) AddAll(newTasks []*model.Task) ([]model.Task, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, newTask := range newTasks {
+			if err := tx.Create(newTask).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]model.Task, 0, len(newTasks))
+	for _, newTask := range newTasks {
+		task, err := r.First(newTask.UUID)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, nil
+}
+
 func (r *Task) Update(task *model.Task) (*model.Task, error) {
 	task.Client = nil // will be re-linked during save
 	db := r.DB.Session(&gorm.Session{FullSaveAssociations: true}).Save(task)
@@ -69,6 +195,41 @@ func (r *Task) Update(task *model.Task) (*model.Task, error) {
 	return r.First(task.UUID)
 }
 
+// UpdateProcessed saves the copy of a task held by its processing node like Update, but keeps the stored priority
+// as it may have been edited after the task was claimed
+func (r *Task) UpdateProcessed(task *model.Task) (*model.Task, error) {
+	task.Client = nil // will be re-linked during save
+	db := r.DB.Session(&gorm.Session{FullSaveAssociations: true}).Omit("priority").Save(task)
+	if db.Error != nil {
+		return task, db.Error
+	}
+	return r.First(task.UUID)
+}
+
+// UpdatePriority changes only the priority of the task if its stored status still equals the given one; returns nil otherwise
+func (r *Task) UpdatePriority(uuid string, status dto.TaskStatus, priority uint) (*model.Task, error) {
+	db := r.DB.Model(&model.Task{}).Where("uuid = ? AND status = ?", uuid, status).Update("priority", priority)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	if db.RowsAffected == 0 {
+		return nil, nil
+	}
+	return r.First(uuid)
+}
+
+// UpdateIfStatus saves the task (without its associations) only if its stored status still equals the given one; returns nil otherwise
+func (r *Task) UpdateIfStatus(task *model.Task, status dto.TaskStatus) (*model.Task, error) {
+	db := r.DB.Model(task).Where("status = ?", status).Select("*").Omit(clause.Associations).Updates(task)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	if db.RowsAffected == 0 {
+		return nil, nil
+	}
+	return r.First(task.UUID)
+}
+
 func (r *Task) Count() (int64, error) {
 	var count int64
 	db := r.DB.Model(&model.Task{}).Count(&count)
@@ -81,6 +242,24 @@ func (r *Task) CountUnfinishedByBatch(uuid string) (int64, error) {
 	return count, db.Error
 }
 
+// ListPendingDependents returns all queued or paused tasks that depend on the given task
+func (r *Task) ListPendingDependents(uuid string) (*[]model.Task, error) {
+	var tasks = &[]model.Task{}
+	db := r.DB.Preload("Client").Preload("Dependencies").Preload("Labels").
+		Where(`status IN ? AND uuid IN (SELECT task FROM "taskDependency" WHERE depends_on = ?)`, []dto.TaskStatus{dto.Queued, dto.Paused}, uuid).
+		Find(tasks)
+	return tasks, db.Error
+}
+
+// ListRunningByClient returns all tasks being processed by the given client
+func (r *Task) ListRunningByClient(identifier string) (*[]model.Task, error) {
+	var tasks = &[]model.Task{}
+	db := r.DB.Preload("Client").Preload("Dependencies").Preload("Labels").
+		Where("client_identifier = ? AND (status IN ? OR (status = ? AND started_at > 0))", identifier, []dto.TaskStatus{dto.Running, dto.PreProcessing, dto.PostProcessing}, dto.Paused).
+		Find(tasks)
+	return tasks, db.Error
+}
+
 /**
  * Stats (systray) related methods
  */
@@ -118,22 +297,161 @@ func (r *Task) CountAllStatus() (queued, running, doneSuccessful, doneError, don
 	return
 }
 
+/**
+ * Batch related methods
+ */
+
+// ListBatches returns the uuids of all batches, newest first
+func (r *Task) ListBatches(page int, perPage int) ([]string, int64, error) {
+	var total int64
+	if err := r.DB.Model(&model.Task{}).Where("batch <> ''").Distinct("batch").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var batches []string
+	err := r.DB.Model(&model.Task{}).Where("batch <> ''").Group("batch").Order("MIN(created_at) DESC").Offset(page*perPage).Limit(perPage).Pluck("batch", &batches).Error
+	return batches, total, err
+}
+
+type batchStatus struct {
+	Batch      string
+	Status     dto.TaskStatus
+	Count      int64
+	Progress   float64
+	CreatedAt  int64
+	StartedAt  int64
+	FinishedAt int64
+}
+
+// SummarizeBatches aggregates the tasks of the given batches; unknown batches are omitted
+func (r *Task) SummarizeBatches(batches []string) (map[string]*dto.BatchSummary, error) {
+	var rows []batchStatus
+	err := r.DB.Model(&model.Task{}).
+		Select("batch, status, COUNT(*) AS count, SUM(progress) AS progress, MIN(created_at) AS created_at, MIN(CASE WHEN started_at > 0 THEN started_at END) AS started_at, MAX(finished_at) AS finished_at").
+		Where("batch IN ?", batches).
+		Group("batch, status").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := map[string]*dto.BatchSummary{}
+	for _, row := range rows {
+		s, ok := summaries[row.Batch]
+		if !ok {
+			s = &dto.BatchSummary{Counts: map[dto.TaskStatus]int64{}, CreatedAt: row.CreatedAt}
+			summaries[row.Batch] = s
+		}
+		s.Counts[row.Status] = row.Count
+		s.Total += row.Count
+		s.CreatedAt = min(s.CreatedAt, row.CreatedAt)
+		if row.StartedAt > 0 && (s.StartedAt == 0 || row.StartedAt < s.StartedAt) {
+			s.StartedAt = row.StartedAt
+		}
+		s.FinishedAt = max(s.FinishedAt, row.FinishedAt)
+
+		// finished tasks count as fully progressed
+		switch row.Status {
+		case dto.DoneSuccessful, dto.DoneError, dto.DoneCanceled:
+			s.Progress += float64(row.Count) * 100
+		default:
+			s.Progress += row.Progress
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	for _, s := range summaries {
+		s.Progress /= float64(s.Total)
+		s.Status = batchStatusOf(s.Counts)
+
+		unfinished := s.Total - s.Counts[dto.DoneSuccessful] - s.Counts[dto.DoneError] - s.Counts[dto.DoneCanceled]
+		switch {
+		case unfinished == 0:
+			s.Remaining = 0
+		case s.StartedAt > 0 && s.Progress > 0:
+			s.Remaining = float64(now-s.StartedAt) / 1000 * (100 - s.Progress) / s.Progress
+			s.FinishedAt = 0
+		default:
+			s.Remaining = -1
+			s.FinishedAt = 0
+		}
+	}
+
+	return summaries, nil
+}
+
+// batchStatusOf derives the status of a batch from the status of its tasks
+func batchStatusOf(counts map[dto.TaskStatus]int64) dto.TaskStatus {
+	switch {
+	case counts[dto.Running]+counts[dto.PreProcessing]+counts[dto.PostProcessing] > 0:
+		return dto.Running
+	case counts[dto.Queued] > 0:
+		return dto.Queued
+	case counts[dto.Paused] > 0:
+		return dto.Paused
+	case counts[dto.DoneError] > 0:
+		return dto.DoneError
+	case counts[dto.DoneCanceled] > 0:
+		return dto.DoneCanceled
+	}
+	return dto.DoneSuccessful
+}
+
 /**
  * Processing related methods
  */
 
-func (r *Task) NextQueued(amount int) (*[]model.Task, error) {
+// queuedPageSize is the amount of queued candidates read at once while looking for claimable tasks
+const queuedPageSize = 20
+
+// NextQueued claims up to amount queued tasks whose required labels are all provided by the given (client) labels.
+// Candidates are read page by page and only those passing accept (if set) are claimed; reading stops once amount
+// tasks are claimed, the queue is exhausted or accept reports that no further task can be claimed.
+func (r *Task) NextQueued(amount int, labels []string, accept func(task *model.Task) (claim bool, more bool)) (*[]model.Task, error) {
 	var tasks []model.Task
+	now := time.Now().UnixMilli()
+
+	// tasks requiring labels are only claimable by clients providing all of them
+	labelFilter := `NOT EXISTS (SELECT 1 FROM "taskLabel" l WHERE l.task = tasks.uuid)`
+	var labelArgs []any
+	if len(labels) > 0 {
+		labelFilter = `NOT EXISTS (SELECT 1 FROM "taskLabel" l WHERE l.task = tasks.uuid AND l.label NOT IN ?)`
+		labelArgs = []any{labels}
+	}
 
 	err := r.DB.Transaction(func(tx *gorm.DB) error {
-		// Select tasks with FOR UPDATE
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Preload("Client").
-			Order("priority DESC, created_at ASC").
-			Where("status = ?", dto.Queued).
-			Limit(amount).
-			Find(&tasks).Error; err != nil {
-			return err
+		pageSize := max(amount, queuedPageSize)
+		for offset, more := 0, true; more && len(tasks) < amount; offset += pageSize {
+			// Select tasks with FOR UPDATE
+			var candidates []model.Task
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Preload("Client").
+				Preload("Dependencies").
+				Preload("Labels").
+				Order("priority DESC, created_at ASC, id ASC").
+				Where("status = ? AND retry_at <= ? AND scheduled_at <= ?", dto.Queued, now, now).
+				Where(`NOT EXISTS (SELECT 1 FROM "taskDependency" d JOIN tasks p ON p.uuid = d.depends_on WHERE d.task = tasks.uuid AND p.status != ?)`, dto.DoneSuccessful).
+				Where(labelFilter, labelArgs...).
+				Limit(pageSize).
+				Offset(offset).
+				Find(&candidates).Error; err != nil {
+				return err
+			}
+			more = len(candidates) == pageSize
+
+			for _, candidate := range candidates {
+				claim, next := true, true
+				if accept != nil {
+					claim, next = accept(&candidate)
+				}
+				if claim {
+					tasks = append(tasks, candidate)
+				}
+				if !next || len(tasks) == amount {
+					more = false
+					break
+				}
+			}
 		}
 
 		if len(tasks) == 0 {
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5/database"
+)
+
+type TaskLog struct {
+	DB *gorm.DB
+}
+
+func (r *TaskLog) Setup() *TaskLog {
+	_ = r.DB.AutoMigrate(&model.TaskLog{})
+	return r
+}
+
+func (r *TaskLog) Add(taskLogs []*model.TaskLog) error {
+	if len(taskLogs) == 0 {
+		return nil
+	}
+	db := r.DB.CreateInBatches(taskLogs, 100)
+	return db.Error
+}
+
+func (r *TaskLog) ListByTask(uuid string, page int, perPage int) (*[]model.TaskLog, int64, error) {
+	var taskLogs = &[]model.TaskLog{}
+	tx := r.DB.Order("id ASC").Where("task = ?", uuid)
+	d := database.NewPaginator(tx, page+1, perPage, taskLogs)
+	err := d.Find()
+	return d.Records, d.Total, err
+}
+
+func (r *TaskLog) DeleteByTask(uuid string) error {
+	db := r.DB.Where("task = ?", uuid).Delete(&model.TaskLog{})
+	return db.Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -43,7 +44,139 @@ func TestNextQueued(t *testing.T) {
 	server := testserver.New(t)
 	repo := (&Task{DB: server.DB()}).Setup()
 
-	q, err := repo.NextQueued(3)
+	q, err := repo.NextQueued(3, nil, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, q)
 }
+
+func TestNextQueuedSkipsPendingRetries(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, RetryAt: time.Now().Add(time.Hour).UnixMilli()})
+	ready, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, RetryAt: time.Now().Add(-time.Second).UnixMilli()})
+
+	q, err := repo.NextQueued(3, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, ready.UUID, (*q)[0].UUID)
+}
+
+func TestNextQueuedSkipsUnfinishedDependencies(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	parent, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Running})
+	child, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Dependencies: []model.TaskDependency{{DependsOn: parent.UUID}}})
+
+	q, err := repo.NextQueued(3, nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, q)
+
+	parent.Status = dto.DoneSuccessful
+	_, _ = repo.Update(parent)
+
+	q, err = repo.NextQueued(3, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, child.UUID, (*q)[0].UUID)
+	assert.Equal(t, parent.UUID, (*q)[0].Dependencies[0].DependsOn)
+}
+
+func TestListRunningByClient(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	running, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Running, ClientIdentifier: "node-a"})
+	paused, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Paused, StartedAt: 1, ClientIdentifier: "node-a"})
+	_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Paused, ClientIdentifier: "node-a"})
+	_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, ClientIdentifier: "node-a"})
+	_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Running, ClientIdentifier: "node-b"})
+
+	tasks, err := repo.ListRunningByClient("node-a")
+	assert.NoError(t, err)
+	assert.Len(t, *tasks, 2)
+	assert.ElementsMatch(t, []string{running.UUID, paused.UUID}, []string{(*tasks)[0].UUID, (*tasks)[1].UUID})
+}
+
+func TestNextQueuedSkipsScheduledTasks(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, ScheduledAt: time.Now().Add(time.Hour).UnixMilli()})
+	due, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, ScheduledAt: time.Now().Add(-time.Second).UnixMilli()})
+
+	q, err := repo.NextQueued(3, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, due.UUID, (*q)[0].UUID)
+}
+
+func TestNextQueuedMatchesLabels(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	gpu, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 2, Labels: []model.TaskLabel{{Label: "gpu=nvenc"}}})
+	berlin, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 1, Labels: []model.TaskLabel{{Label: "gpu=nvenc"}, {Label: "site=berlin"}}})
+	unlabeled, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued})
+
+	q, err := repo.NextQueued(3, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, unlabeled.UUID, (*q)[0].UUID)
+
+	q, err = repo.NextQueued(3, []string{"gpu=nvenc", "site=paris"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, gpu.UUID, (*q)[0].UUID)
+
+	q, err = repo.NextQueued(3, []string{"gpu=nvenc", "site=berlin"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, berlin.UUID, (*q)[0].UUID)
+	assert.Len(t, (*q)[0].Labels, 2)
+}
+
+func TestNextQueuedAccept(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 2, Preset: "limited"})
+	other, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 1})
+
+	q, err := repo.NextQueued(3, nil, func(task *model.Task) (bool, bool) { return task.Preset != "limited", true })
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, other.UUID, (*q)[0].UUID)
+	assert.Equal(t, uint(1), (*q)[0].Weight)
+
+	q, err = repo.NextQueued(3, nil, func(task *model.Task) (bool, bool) { return false, true })
+	assert.NoError(t, err)
+	assert.Nil(t, q)
+}
+
+func TestNextQueuedPagesPastRejectedTasks(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&Task{DB: server.DB()}).Setup()
+
+	for range queuedPageSize + 5 {
+		_, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 2, Preset: "limited"})
+	}
+	other, _ := repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 1})
+
+	q, err := repo.NextQueued(1, nil, func(task *model.Task) (bool, bool) { return task.Preset != "limited", true })
+	assert.NoError(t, err)
+	assert.Len(t, *q, 1)
+	assert.Equal(t, other.UUID, (*q)[0].UUID)
+
+	// no further candidates are read once accept reports that nothing more fits
+	other, _ = repo.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Queued, Priority: 1})
+	var considered int
+	q, err = repo.NextQueued(1, nil, func(task *model.Task) (bool, bool) {
+		considered++
+		return task.Preset != "limited", false
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, q)
+	assert.Equal(t, 1, considered)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"goyave.dev/goyave/v5/database"
+)
+
+type WebhookDelivery struct {
+	DB *gorm.DB
+}
+
+func (r *WebhookDelivery) Setup() *WebhookDelivery {
+	_ = r.DB.AutoMigrate(&model.WebhookDelivery{})
+	return r
+}
+
+func (r *WebhookDelivery) Add(delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	db := r.DB.Create(delivery)
+	return delivery, db.Error
+}
+
+func (r *WebhookDelivery) Update(delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	db := r.DB.Save(delivery)
+	return delivery, db.Error
+}
+
+func (r *WebhookDelivery) Delete(delivery *model.WebhookDelivery) error {
+	return r.DB.Delete(delivery).Error
+}
+
+func (r *WebhookDelivery) First(uuid string) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	result := r.DB.Where("uuid = ?", uuid).First(&delivery)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookDelivery) List(page int, perPage int, status []dto.WebhookDeliveryStatus) (*[]model.WebhookDelivery, int64, error) {
+	var deliveries = &[]model.WebhookDelivery{}
+	tx := r.DB.Order("created_at DESC, id DESC")
+	if len(status) > 0 {
+		tx = tx.Where("status IN ?", status)
+	}
+	d := database.NewPaginator(tx, page+1, perPage, deliveries)
+	err := d.Find()
+	return d.Records, d.Total, err
+}
+
+// NextDue claims up to amount pending deliveries that are due and deliveries whose claim is older than staleBefore (unix ms)
+func (r *WebhookDelivery) NextDue(amount int, identifier string, staleBefore int64) (*[]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	now := time.Now().UnixMilli()
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND claimed_at < ?)", dto.DeliveryPending, now, dto.DeliveryDelivering, staleBefore).
+			Order("next_attempt_at ASC, id ASC").
+			Limit(amount).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].Status = dto.DeliveryDelivering
+			deliveries[i].ClaimedBy = identifier
+			deliveries[i].ClaimedAt = now
+		}
+
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{"status": dto.DeliveryDelivering, "claimed_by": identifier, "claimed_at": now}).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &deliveries, err
+}
+
+// Claimed lists the deliveries currently claimed by the identifier
+func (r *WebhookDelivery) Claimed(identifier string) (*[]model.WebhookDelivery, error) {
+	var deliveries = &[]model.WebhookDelivery{}
+	err := r.DB.Where("status = ? AND claimed_by = ?", dto.DeliveryDelivering, identifier).Order("id ASC").Find(deliveries).Error
+	return deliveries, err
+}
+
+// Release puts the delivery back into the queue if it is still claimed with the given claim time (unix ms); returns whether it was released
+func (r *WebhookDelivery) Release(uuid string, claimedAt int64) (bool, error) {
+	db := r.DB.Model(&model.WebhookDelivery{}).
+		Where("uuid = ? AND status = ? AND claimed_at = ?", uuid, dto.DeliveryDelivering, claimedAt).
+		Updates(map[string]any{"status": dto.DeliveryPending, "claimed_by": "", "claimed_at": 0})
+	return db.RowsAffected > 0, db.Error
+}
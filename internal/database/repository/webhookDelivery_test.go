@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/testsuite/testserver"
+)
+
+func TestWebhookDeliveryNextDue(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&WebhookDelivery{DB: server.DB()}).Setup()
+	now := time.Now()
+
+	due := &model.WebhookDelivery{UUID: uuid.NewString(), Status: dto.DeliveryPending, NextAttemptAt: now.Add(-time.Second).UnixMilli()}
+	later := &model.WebhookDelivery{UUID: uuid.NewString(), Status: dto.DeliveryPending, NextAttemptAt: now.Add(time.Hour).UnixMilli()}
+	stale := &model.WebhookDelivery{UUID: uuid.NewString(), Status: dto.DeliveryDelivering, ClaimedBy: "gone", ClaimedAt: now.Add(-time.Hour).UnixMilli()}
+	claimed := &model.WebhookDelivery{UUID: uuid.NewString(), Status: dto.DeliveryDelivering, ClaimedBy: "other", ClaimedAt: now.UnixMilli()}
+	dead := &model.WebhookDelivery{UUID: uuid.NewString(), Status: dto.DeliveryDeadLetter}
+	server.DB().Create([]*model.WebhookDelivery{due, later, stale, claimed, dead})
+
+	deliveries, err := repo.NextDue(10, "me", now.Add(-time.Minute).UnixMilli())
+	assert.NoError(t, err)
+	assert.Len(t, *deliveries, 2)
+	for _, delivery := range *deliveries {
+		assert.Contains(t, []string{due.UUID, stale.UUID}, delivery.UUID)
+		assert.Equal(t, dto.DeliveryDelivering, delivery.Status)
+		assert.Equal(t, "me", delivery.ClaimedBy)
+	}
+
+	// claimed deliveries are not handed out twice
+	deliveries, err = repo.NextDue(10, "other", now.Add(-time.Minute).UnixMilli())
+	assert.NoError(t, err)
+	assert.Nil(t, deliveries)
+
+	mine, err := repo.Claimed("me")
+	assert.NoError(t, err)
+	assert.Len(t, *mine, 2)
+
+	// a delivery claimed again in the meantime is not released
+	released, err := repo.Release((*mine)[0].UUID, (*mine)[0].ClaimedAt-1)
+	assert.NoError(t, err)
+	assert.False(t, released)
+
+	for _, delivery := range *mine {
+		released, err = repo.Release(delivery.UUID, delivery.ClaimedAt)
+		assert.NoError(t, err)
+		assert.True(t, released)
+	}
+
+	list, total, err := repo.List(0, 10, []dto.WebhookDeliveryStatus{dto.DeliveryPending})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, *list, 3)
+}
@@ -1,7 +1,12 @@
 package repository
 
 import (
+	"errors"
+	"math"
+	"time"
+
 	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"gorm.io/gorm"
 	"goyave.dev/goyave/v5/database"
 )
@@ -20,10 +25,95 @@ func (r *WebhookExecution) Add(newWebhookExecution *model.WebhookExecution) (*mo
 	return newWebhookExecution, db.Error
 }
 
-func (r *WebhookExecution) List(page int, perPage int) (*[]model.WebhookExecution, int64, error) {
+// filter applies the conditions of the given filter
+func (r *WebhookExecution) filter(tx *gorm.DB, filter *dto.WebhookExecutionFilter) *gorm.DB {
+	if len(filter.Event) > 0 {
+		tx = tx.Where("event IN ?", filter.Event)
+	}
+	if filter.Webhook != "" {
+		tx = tx.Where("webhook = ?", filter.Webhook)
+	}
+	if filter.Task != "" {
+		tx = tx.Where("task = ?", filter.Task)
+	}
+	if filter.StatusFrom > 0 || filter.StatusTo > 0 {
+		tx = tx.Where("response_status > 0")
+	}
+	if filter.StatusFrom > 0 {
+		tx = tx.Where("response_status >= ?", filter.StatusFrom)
+	}
+	if filter.StatusTo > 0 {
+		tx = tx.Where("response_status <= ?", filter.StatusTo)
+	}
+	if filter.CreatedFrom > 0 {
+		tx = tx.Where("created_at >= ?", time.UnixMilli(filter.CreatedFrom))
+	}
+	if filter.CreatedTo > 0 {
+		tx = tx.Where("created_at <= ?", time.UnixMilli(filter.CreatedTo))
+	}
+	return tx
+}
+
+func (r *WebhookExecution) List(page int, perPage int, filter *dto.WebhookExecutionFilter) (*[]model.WebhookExecution, int64, error) {
 	var webhookExecutions = &[]model.WebhookExecution{}
-	tx := r.DB.Order("created_at DESC")
+	if filter == nil {
+		filter = &dto.WebhookExecutionFilter{}
+	}
+	tx := r.filter(r.DB, filter).Order("created_at DESC").Order("id DESC")
 	d := database.NewPaginator(tx, page+1, perPage, webhookExecutions)
 	err := d.Find()
 	return d.Records, d.Total, err
 }
+
+func (r *WebhookExecution) First(uuid string) (*model.WebhookExecution, error) {
+	var webhookExecution model.WebhookExecution
+	result := r.DB.Where("uuid = ?", uuid).First(&webhookExecution)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &webhookExecution, nil
+}
+
+// CountByWebhook returns the number of all and of the successful (2xx) executions of a webhook
+func (r *WebhookExecution) CountByWebhook(webhook string) (int64, int64, error) {
+	var total, successful int64
+	if err := r.DB.Model(&model.WebhookExecution{}).Where("webhook = ?", webhook).Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	err := r.DB.Model(&model.WebhookExecution{}).Where("webhook = ? AND response_status BETWEEN 200 AND 299", webhook).Count(&successful).Error
+	return total, successful, err
+}
+
+// DurationPercentile returns the nearest-rank percentile of the durations of all executions of a webhook that received a response;
+// only the single duration at the rank is loaded
+func (r *WebhookExecution) DurationPercentile(webhook string, p float64) (int64, error) {
+	var count int64
+	if err := r.DB.Model(&model.WebhookExecution{}).Where("webhook = ? AND response_status > 0", webhook).Count(&count).Error; err != nil || count == 0 {
+		return 0, err
+	}
+	rank := max(int(math.Ceil(p/100*float64(count))), 1)
+
+	var durations []int64
+	err := r.DB.Model(&model.WebhookExecution{}).Where("webhook = ? AND response_status > 0", webhook).
+		Order("duration ASC").Order("id ASC").Offset(rank-1).Limit(1).Pluck("duration", &durations).Error
+	if err != nil || len(durations) == 0 {
+		return 0, err
+	}
+	return durations[0], nil
+}
+
+// LastFailure returns the latest execution of a webhook without a 2xx response
+func (r *WebhookExecution) LastFailure(webhook string) (*model.WebhookExecution, error) {
+	var webhookExecution model.WebhookExecution
+	result := r.DB.Where("webhook = ? AND (response_status < 200 OR response_status > 299)", webhook).Order("created_at DESC").Order("id DESC").First(&webhookExecution)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &webhookExecution, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/testsuite/testserver"
+)
+
+func TestWebhookExecutionFilter(t *testing.T) {
+	server := testserver.New(t)
+	repo := (&WebhookExecution{DB: server.DB()}).Setup()
+	now := time.Now()
+
+	ok := &model.WebhookExecution{UUID: uuid.NewString(), Webhook: "a", Task: "t1", Event: dto.TaskCreated, ResponseStatus: 200, Duration: 10, CreatedAt: now.Add(-time.Hour)}
+	failed := &model.WebhookExecution{UUID: uuid.NewString(), Webhook: "a", Task: "t1", Event: dto.TaskUpdated, ResponseStatus: 500, Duration: 30, CreatedAt: now.Add(-time.Minute)}
+	unreachable := &model.WebhookExecution{UUID: uuid.NewString(), Webhook: "a", Event: dto.TaskUpdated, Duration: 1000, CreatedAt: now}
+	other := &model.WebhookExecution{UUID: uuid.NewString(), Webhook: "b", Task: "t2", Event: dto.TaskCreated, ResponseStatus: 204, Duration: 20, CreatedAt: now}
+	server.DB().Create([]*model.WebhookExecution{ok, failed, unreachable, other})
+
+	list, total, err := repo.List(0, 10, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, ok.UUID, (*list)[3].UUID)
+
+	for name, tc := range map[string]struct {
+		filter   dto.WebhookExecutionFilter
+		expected []string
+	}{
+		"webhook":     {dto.WebhookExecutionFilter{Webhook: "b"}, []string{other.UUID}},
+		"task":        {dto.WebhookExecutionFilter{Task: "t1"}, []string{failed.UUID, ok.UUID}},
+		"event":       {dto.WebhookExecutionFilter{Event: []dto.WebhookEvent{dto.TaskUpdated}}, []string{unreachable.UUID, failed.UUID}},
+		"status":      {dto.WebhookExecutionFilter{StatusFrom: 200, StatusTo: 299}, []string{other.UUID, ok.UUID}},
+		"statusTo":    {dto.WebhookExecutionFilter{Webhook: "a", StatusTo: 499}, []string{ok.UUID}},
+		"createdFrom": {dto.WebhookExecutionFilter{Webhook: "a", CreatedFrom: now.Add(-30 * time.Minute).UnixMilli()}, []string{unreachable.UUID, failed.UUID}},
+		"createdTo":   {dto.WebhookExecutionFilter{CreatedTo: now.Add(-30 * time.Minute).UnixMilli()}, []string{ok.UUID}},
+	} {
+		list, total, err := repo.List(0, 10, &tc.filter)
+		assert.NoError(t, err, name)
+		assert.Equal(t, int64(len(tc.expected)), total, name)
+		var uuids []string
+		for _, execution := range *list {
+			uuids = append(uuids, execution.UUID)
+		}
+		assert.Equal(t, tc.expected, uuids, name)
+	}
+
+	all, successful, err := repo.CountByWebhook("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), all)
+	assert.Equal(t, int64(1), successful)
+
+	p50, err := repo.DurationPercentile("a", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), p50)
+	p95, err := repo.DurationPercentile("a", 95)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), p95)
+	none, err := repo.DurationPercentile("unknown", 95)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), none)
+
+	last, err := repo.LastFailure("a")
+	assert.NoError(t, err)
+	assert.Equal(t, unreachable.UUID, last.UUID)
+
+	last, err = repo.LastFailure("b")
+	assert.NoError(t, err)
+	assert.Nil(t, last)
+}
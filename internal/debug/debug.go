@@ -40,6 +40,8 @@ func newLoggers(prefix string) loggers {
 var (
 	Log         = newLoggers("") // no namespace
 	Watchfolder = newLoggers("watchfolder")
+	Schedule    = newLoggers("schedule")
+	Janitor     = newLoggers("janitor")
 	Task        = newLoggers("task")
 	Client      = newLoggers("client")
 	FFmpeg      = newLoggers("ffmpeg")
@@ -5,6 +5,17 @@ type NewBatch struct {
 }
 
 type Batch struct {
-	UUID  string  `json:"uuid"`
-	Tasks []*Task `json:"tasks"`
+	Summary *BatchSummary `json:"summary,omitempty"`
+	UUID    string        `json:"uuid"`
+	Tasks   []*Task       `json:"tasks,omitempty"`
+}
+
+type NewWorkflow struct {
+	Tasks []*NewWorkflowTask `json:"tasks"`
+}
+
+// NewWorkflowTask is a task of a workflow whose dependsOn may reference the ids of other workflow tasks
+type NewWorkflowTask struct {
+	*NewTask
+	ID string `json:"id"`
 }
@@ -0,0 +1,13 @@
+package dto
+
+// BatchSummary aggregates the status of all tasks of a batch
+type BatchSummary struct {
+	Counts     map[TaskStatus]int64 `json:"counts"`
+	Status     TaskStatus           `json:"status"`
+	Total      int64                `json:"total"`
+	Progress   float64              `json:"progress"`
+	Remaining  float64              `json:"remaining"` // estimated seconds until all tasks are finished (-1 if unknown)
+	CreatedAt  int64                `json:"createdAt"`
+	StartedAt  int64                `json:"startedAt,omitempty"`
+	FinishedAt int64                `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+type BulkAction string
+
+const (
+	BulkCancel   BulkAction = "cancel"
+	BulkRestart  BulkAction = "restart"
+	BulkDelete   BulkAction = "delete"
+	BulkPriority BulkAction = "priority"
+)
+
+// NewBulkOperation selects tasks by uuid and/or filter and applies the action to each of them
+type NewBulkOperation struct {
+	Filter   *TaskFilter `json:"filter"`
+	Action   BulkAction  `json:"action"`
+	UUIDs    []string    `json:"uuids"`
+	Priority uint        `json:"priority"`
+	Cleanup  bool        `json:"cleanup"`
+}
+
+type BulkOperation struct {
+	Action    BulkAction  `json:"action"`
+	Succeeded []string    `json:"succeeded"`
+	Failed    []BulkError `json:"failed"`
+}
+
+type BulkError struct {
+	UUID  string `json:"uuid"`
+	Error string `json:"error"`
+}
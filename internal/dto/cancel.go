@@ -0,0 +1,7 @@
+package dto
+
+import "goyave.dev/goyave/v5/util/typeutil"
+
+type CancelTask struct {
+	Cleanup typeutil.Undefined[bool] `json:"cleanup"`
+}
@@ -10,6 +10,11 @@ type Client struct {
 	Version string `json:"version"`
 	FFMpeg  string `json:"ffmpeg"`
 
+	Labels []string `json:"labels,omitempty"`
+
+	Queue   QueueStatus `json:"queue"`
+	Offline bool        `json:"offline"`
+
 	LastSeen int64 `json:"lastSeen"`
 
 	Self bool `json:"self,omitempty"`
@@ -8,5 +8,8 @@ const (
 )
 
 type Health struct {
-	Status HealthStatus `json:"status"`
+	Status       HealthStatus `json:"status"`
+	Queue        QueueStatus  `json:"queue"`
+	RunningTasks int          `json:"runningTasks"`
+	Drained      bool         `json:"drained,omitempty"`
 }
@@ -0,0 +1,11 @@
+package dto
+
+// JanitorReport lists the amount of rows purged (or to be purged on a dry run) by the janitor
+type JanitorReport struct {
+	Tasks             int64 `json:"tasks"`
+	TaskLogs          int64 `json:"taskLogs"`
+	Deleted           int64 `json:"deleted"`
+	WebhookExecutions int64 `json:"webhookExecutions"`
+	DeadLetters       int64 `json:"deadLetters"`
+	DryRun            bool  `json:"dryRun"`
+}
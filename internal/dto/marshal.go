@@ -12,6 +12,9 @@ func (n *WatchfolderFilter) Scan(value any) error        { return scanJSON(n, va
 func (n Settings) Value() (driver.Value, error) { return valueJSON(n) }
 func (n *Settings) Scan(value any) error        { return scanJSON(n, value) }
 
+func (n Retention) Value() (driver.Value, error) { return valueJSON(n) }
+func (n *Retention) Scan(value any) error        { return scanJSON(n, value) }
+
 func (n MetadataMap) Value() (driver.Value, error) { return valueJSON(n) }
 func (n *MetadataMap) Scan(value any) error        { return scanJSON(n, value) }
 
@@ -30,6 +33,9 @@ func (n *Webhook) Scan(value any) error        { return scanJSON(n, value) }
 func (n DirectWebhooks) Value() (driver.Value, error) { return valueJSON(n) }
 func (n *DirectWebhooks) Scan(value any) error        { return scanJSON(n, value) }
 
+func (n PresetRules) Value() (driver.Value, error) { return valueJSON(n) }
+func (n *PresetRules) Scan(value any) error        { return scanJSON(n, value) }
+
 func (n NewWebhook) Value() (driver.Value, error) { return valueJSON(n) }
 func (n *NewWebhook) Scan(value any) error        { return scanJSON(n, value) }
 
@@ -42,6 +48,15 @@ func (p *RawResolved) Scan(value any) error        { return scanJSON(p, value) }
 func (n NewPrePostProcessing) Value() (driver.Value, error) { return valueJSON(n) }
 func (n *NewPrePostProcessing) Scan(value any) error        { return scanJSON(n, value) }
 
+func (n RetryPolicy) Value() (driver.Value, error) { return valueJSON(n) }
+func (n *RetryPolicy) Scan(value any) error        { return scanJSON(n, value) }
+
+func (n Probe) Value() (driver.Value, error) { return valueJSON(n) }
+func (n *Probe) Scan(value any) error        { return scanJSON(n, value) }
+
+func (n TaskAttempts) Value() (driver.Value, error) { return valueJSON(n) }
+func (n *TaskAttempts) Scan(value any) error        { return scanJSON(n, value) }
+
 func scanJSON[T any](dst *T, value any) error {
 	if value == nil {
 		return nil
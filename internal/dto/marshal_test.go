@@ -119,6 +119,14 @@ func TestMarshalScan_RoundTrip(t *testing.T) {
 				return &dst
 			},
 		},
+		{
+			name:     "PresetRules",
+			original: PresetRules{{Conditions: []PresetCondition{{Field: "probe.video.height", Operator: ConditionGte, Value: float64(2160)}}, Preset: "uhd"}, {Preset: "default"}},
+			zero: func() scanner {
+				var dst PresetRules
+				return &dst
+			},
+		},
 		{
 			name:     "NewWebhook",
 			original: NewWebhook{Event: PresetCreated, URL: "https://example.net/new"},
@@ -151,6 +159,22 @@ func TestMarshalScan_RoundTrip(t *testing.T) {
 				return &dst
 			},
 		},
+		{
+			name:     "RetryPolicy",
+			original: RetryPolicy{MaxAttempts: 3, Delay: 10, Backoff: 2, Match: "Connection refused"},
+			zero: func() scanner {
+				var dst RetryPolicy
+				return &dst
+			},
+		},
+		{
+			name:     "TaskAttempts",
+			original: TaskAttempts{{Attempt: 1, StartedAt: 10, FinishedAt: 20, Error: "failed"}},
+			zero: func() scanner {
+				var dst TaskAttempts
+				return &dst
+			},
+		},
 	}
 
 	for _, c := range cases {
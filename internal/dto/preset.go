@@ -6,12 +6,18 @@ type NewPreset struct {
 	Webhooks         *DirectWebhooks       `json:"webhooks"`
 	PreProcessing    *NewPrePostProcessing `json:"preProcessing"`
 	PostProcessing   *NewPrePostProcessing `json:"postProcessing"`
+	Retry            *RetryPolicy          `json:"retry"`
+	Rules            *PresetRules          `json:"rules"`
+	Labels           []string              `json:"labels"`
+	Tags             []string              `json:"tags"`
 	Command          string                `json:"command"`
 	OutputFile       string                `json:"outputFile"`
 	Name             string                `json:"name"`
 	Description      string                `json:"description"`
 	GlobalPresetName string                `json:"globalPresetName"`
 	Priority         uint                  `json:"priority"`
+	Weight           uint                  `json:"weight"`
+	MaxConcurrent    uint                  `json:"maxConcurrent"`
 }
 
 type Preset struct {
@@ -20,10 +26,16 @@ type Preset struct {
 	PreProcessing  *NewPrePostProcessing `json:"preProcessing,omitempty"`
 	PostProcessing *NewPrePostProcessing `json:"postProcessing,omitempty"`
 	Webhooks       *DirectWebhooks       `json:"webhooks,omitempty"`
+	Retry          *RetryPolicy          `json:"retry,omitempty"`
+	Rules          *PresetRules          `json:"rules,omitempty"`
+	Labels         []string              `json:"labels,omitempty"`
+	Tags           []string              `json:"tags,omitempty"`
 	UUID           string                `json:"uuid"`
 	Command        string                `json:"command"`
 	Name           string                `json:"name"`
 	Description    string                `json:"description,omitempty"`
 	OutputFile     string                `json:"outputFile"`
 	Priority       uint                  `json:"priority"`
+	Weight         uint                  `json:"weight,omitempty"`
+	MaxConcurrent  uint                  `json:"maxConcurrent,omitempty"`
 }
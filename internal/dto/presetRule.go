@@ -0,0 +1,48 @@
+package dto
+
+// PresetRules are evaluated in order when a task is created from the preset; the first matching rule selects the preset to use instead
+type PresetRules []PresetRule
+
+type PresetRule struct {
+	Conditions []PresetCondition `json:"conditions"`
+	Preset     string            `json:"preset"`
+}
+
+// PresetCondition compares the value at the (gjson) path of the field against the value,
+// e.g. "probe.video.height", "file.extension" or "metadata.customer"
+type PresetCondition struct {
+	Value    any               `json:"value,omitempty"`
+	Field    string            `json:"field"`
+	Operator ConditionOperator `json:"operator"`
+}
+
+type ConditionOperator string
+
+const (
+	ConditionEq        ConditionOperator = "eq"
+	ConditionNe        ConditionOperator = "ne"
+	ConditionGt        ConditionOperator = "gt"
+	ConditionGte       ConditionOperator = "gte"
+	ConditionLt        ConditionOperator = "lt"
+	ConditionLte       ConditionOperator = "lte"
+	ConditionContains  ConditionOperator = "contains"
+	ConditionMatches   ConditionOperator = "matches"
+	ConditionExists    ConditionOperator = "exists"
+	ConditionNotExists ConditionOperator = "notExists"
+)
+
+// PresetRuleSubject is the document the conditions of preset rules are evaluated against
+type PresetRuleSubject struct {
+	Probe    *Probe       `json:"probe,omitempty"`
+	Metadata *MetadataMap `json:"metadata,omitempty"`
+	File     RuleFile     `json:"file"`
+	Source   TaskSource   `json:"source"`
+}
+
+type RuleFile struct {
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Basename  string `json:"basename"`
+	Extension string `json:"extension"`
+	Dir       string `json:"dir"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+type NewProbe struct {
+	InputFile string `json:"inputFile"`
+}
+
+// Probe contains the format and stream information of a media file reported by ffprobe
+type Probe struct {
+	Video   *ProbeStream  `json:"video,omitempty"` // first video stream
+	Audio   *ProbeStream  `json:"audio,omitempty"` // first audio stream
+	Streams []ProbeStream `json:"streams"`
+	Format  ProbeFormat   `json:"format"`
+}
+
+type ProbeFormat struct {
+	Tags       map[string]string `json:"tags,omitempty"`
+	Filename   string            `json:"filename"`
+	FormatName string            `json:"formatName"`
+	Duration   float64           `json:"duration"`
+	Size       int64             `json:"size"`
+	BitRate    int64             `json:"bitRate"`
+}
+
+type ProbeStream struct {
+	CodecType     string  `json:"codecType"`
+	CodecName     string  `json:"codecName"`
+	Profile       string  `json:"profile,omitempty"`
+	PixelFormat   string  `json:"pixelFormat,omitempty"`
+	ChannelLayout string  `json:"channelLayout,omitempty"`
+	Language      string  `json:"language,omitempty"`
+	Index         int     `json:"index"`
+	Width         int     `json:"width,omitempty"`
+	Height        int     `json:"height,omitempty"`
+	FrameRate     float64 `json:"frameRate,omitempty"`
+	SampleRate    int     `json:"sampleRate,omitempty"`
+	Channels      int     `json:"channels,omitempty"`
+	Duration      float64 `json:"duration,omitempty"`
+	BitRate       int64   `json:"bitRate,omitempty"`
+}
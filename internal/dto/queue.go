@@ -0,0 +1,18 @@
+package dto
+
+type QueueStatus string
+
+const (
+	QueueActive   QueueStatus = "active"
+	QueuePaused   QueueStatus = "paused"
+	QueueDraining QueueStatus = "draining" // finishes the running tasks and becomes drained
+	QueueDrained  QueueStatus = "drained"
+)
+
+type Queue struct {
+	Status QueueStatus `json:"status"`
+}
+
+type NewQueue struct {
+	Status QueueStatus `json:"status"`
+}
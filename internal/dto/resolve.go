@@ -0,0 +1,21 @@
+package dto
+
+// ResolvedTask shows how a new task would be resolved at processing time
+type ResolvedTask struct {
+	PreProcessing    *ResolvedProcessing `json:"preProcessing,omitempty"`
+	PostProcessing   *ResolvedProcessing `json:"postProcessing,omitempty"`
+	Probe            *Probe              `json:"probe,omitempty"`
+	Argv             [][]string          `json:"argv"`
+	UnknownWildcards []string            `json:"unknownWildcards"`
+	Command          string              `json:"command"`
+	Preset           string              `json:"preset,omitempty"`
+	InputFile        string              `json:"inputFile"`
+	OutputFile       string              `json:"outputFile"`
+	Error            string              `json:"error,omitempty"`
+	ProbeError       string              `json:"probeError,omitempty"`
+}
+
+type ResolvedProcessing struct {
+	ScriptPath  string `json:"scriptPath,omitempty"`
+	SidecarPath string `json:"sidecarPath,omitempty"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+type RetryPolicy struct {
+	Match       string  `json:"match,omitempty"`
+	MaxAttempts uint    `json:"maxAttempts"`
+	Delay       uint    `json:"delay"`
+	Backoff     float64 `json:"backoff,omitempty"`
+}
+
+// retryMatchers caches the compiled match expressions by pattern
+var retryMatchers = sync.Map{}
+
+// Matcher returns the compiled match expression of the policy, or nil if every error is retried.
+// Each pattern is compiled only once.
+func (p *RetryPolicy) Matcher() (*regexp.Regexp, error) {
+	if p == nil || p.Match == "" {
+		return nil, nil
+	}
+	if re, ok := retryMatchers.Load(p.Match); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(p.Match)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry match expression: %w", err)
+	}
+	retryMatchers.Store(p.Match, re)
+	return re, nil
+}
+
+type TaskAttempt struct {
+	Error      string `json:"error,omitempty"`
+	Attempt    uint   `json:"attempt"`
+	StartedAt  int64  `json:"startedAt,omitempty"`
+	FinishedAt int64  `json:"finishedAt,omitempty"`
+}
+
+type TaskAttempts []TaskAttempt
@@ -0,0 +1,31 @@
+package dto
+
+type NewSchedule struct {
+	Metadata    *MetadataMap `json:"metadata"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Cron        string       `json:"cron"`
+	Preset      string       `json:"preset"`
+	InputFile   string       `json:"inputFile"` // a single file or a glob pattern, directories are rejected
+	OutputFile  string       `json:"outputFile"`
+	Priority    *uint        `json:"priority"` // forwarded to the created tasks if set, including an explicit 0
+	Suspended   bool         `json:"suspended"`
+}
+
+type Schedule struct {
+	Metadata    *MetadataMap `json:"metadata,omitempty"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Cron        string       `json:"cron"`
+	Preset      string       `json:"preset"`
+	InputFile   string       `json:"inputFile"`
+	OutputFile  string       `json:"outputFile"`
+	Error       string       `json:"error,omitempty"`
+	UUID        string       `json:"uuid"`
+	Priority    *uint        `json:"priority,omitempty"`
+	CreatedAt   int64        `json:"createdAt"`
+	UpdatedAt   int64        `json:"updatedAt"`
+	LastRun     int64        `json:"lastRun,omitempty"`
+	NextRun     int64        `json:"nextRun,omitempty"`
+	Suspended   bool         `json:"suspended"`
+}
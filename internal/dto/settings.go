@@ -1,4 +1,25 @@
 package dto
 
 type Settings struct {
+	Retention          *Retention     `json:"retention,omitempty"`
+	MaxConcurrentTasks *uint          `json:"maxConcurrentTasks,omitempty"`
+	DefaultPriority    *uint          `json:"defaultPriority,omitempty"`
+	Nodes              []NodeSettings `json:"nodes,omitempty"`
+}
+
+// NodeSettings override the settings for a single client
+type NodeSettings struct {
+	MaxConcurrentTasks *uint  `json:"maxConcurrentTasks,omitempty"`
+	DefaultPriority    *uint  `json:"defaultPriority,omitempty"`
+	Identifier         string `json:"identifier"`
+}
+
+// Retention defines after how many days finished tasks, soft-deleted rows and dead-lettered webhook deliveries are purged and how many webhook executions are kept (0 keeps them forever)
+type Retention struct {
+	DoneSuccessful    uint `json:"doneSuccessful"`
+	DoneError         uint `json:"doneError"`
+	DoneCanceled      uint `json:"doneCanceled"`
+	Deleted           uint `json:"deleted"`
+	WebhookExecutions uint `json:"webhookExecutions"`
+	DeadLetters       uint `json:"deadLetters"`
 }
@@ -1,10 +1,13 @@
 package dto
 
+import "goyave.dev/goyave/v5/util/typeutil"
+
 type TaskSource string
 
 const (
 	API         TaskSource = "api"
 	WATCHFOLDER TaskSource = "watchfolder"
+	SCHEDULE    TaskSource = "schedule"
 )
 
 type TaskStatus string
@@ -17,19 +20,43 @@ const (
 	DoneSuccessful TaskStatus = "DONE_SUCCESSFUL"
 	DoneError      TaskStatus = "DONE_ERROR"
 	DoneCanceled   TaskStatus = "DONE_CANCELED"
+	Paused         TaskStatus = "PAUSED"
 )
 
 type NewTask struct {
-	Metadata       *MetadataMap          `json:"metadata"`
-	Webhooks       *DirectWebhooks       `json:"webhooks"`
-	PreProcessing  *NewPrePostProcessing `json:"preProcessing"`
-	PostProcessing *NewPrePostProcessing `json:"postProcessing"`
-	Command        string                `json:"command"`
-	Preset         string                `json:"preset"`
-	Name           string                `json:"name"`
-	InputFile      string                `json:"inputFile"`
-	OutputFile     string                `json:"outputFile"`
-	Priority       uint                  `json:"priority"`
+	Metadata       *MetadataMap             `json:"metadata"`
+	Webhooks       *DirectWebhooks          `json:"webhooks"`
+	PreProcessing  *NewPrePostProcessing    `json:"preProcessing"`
+	PostProcessing *NewPrePostProcessing    `json:"postProcessing"`
+	Retry          *RetryPolicy             `json:"retry"`
+	DependsOn      []string                 `json:"dependsOn"`
+	Labels         []string                 `json:"labels"`
+	Tags           []string                 `json:"tags"`
+	Command        string                   `json:"command"`
+	Preset         string                   `json:"preset"`
+	Name           string                   `json:"name"`
+	InputFile      string                   `json:"inputFile"`
+	OutputFile     string                   `json:"outputFile"`
+	Priority       typeutil.Undefined[uint] `json:"priority,omitzero"` // defaults to the preset's priority, then to the default priority setting
+	Weight         uint                     `json:"weight"`
+	ScheduledAt    int64                    `json:"scheduledAt"`
+}
+
+// UpdateTask contains the editable fields of a task; fields that are not present remain unchanged
+type UpdateTask struct {
+	Metadata       typeutil.Undefined[*MetadataMap]          `json:"metadata"`
+	Webhooks       typeutil.Undefined[*DirectWebhooks]       `json:"webhooks"`
+	PreProcessing  typeutil.Undefined[*NewPrePostProcessing] `json:"preProcessing"`
+	PostProcessing typeutil.Undefined[*NewPrePostProcessing] `json:"postProcessing"`
+	Retry          typeutil.Undefined[*RetryPolicy]          `json:"retry"`
+	Tags           typeutil.Undefined[[]string]              `json:"tags"`
+	Command        typeutil.Undefined[string]                `json:"command"`
+	Name           typeutil.Undefined[string]                `json:"name"`
+	InputFile      typeutil.Undefined[string]                `json:"inputFile"`
+	OutputFile     typeutil.Undefined[string]                `json:"outputFile"`
+	Priority       typeutil.Undefined[uint]                  `json:"priority"`
+	Weight         typeutil.Undefined[uint]                  `json:"weight"`
+	ScheduledAt    typeutil.Undefined[int64]                 `json:"scheduledAt"`
 }
 
 type Task struct {
@@ -41,8 +68,15 @@ type Task struct {
 	OutputFile     *RawResolved       `json:"outputFile"`
 	Metadata       *MetadataMap       `json:"metadata,omitempty"`
 	Webhooks       *DirectWebhooks    `json:"webhooks,omitempty"`
+	Retry          *RetryPolicy       `json:"retry,omitempty"`
+	Attempts       *TaskAttempts      `json:"attempts,omitempty"`
+	Probe          *Probe             `json:"probe,omitempty"`
+	DependsOn      []string           `json:"dependsOn,omitempty"`
+	Labels         []string           `json:"labels,omitempty"`
+	Tags           []string           `json:"tags,omitempty"`
 	Status         TaskStatus         `json:"status"`
 	Name           string             `json:"name,omitempty"`
+	Preset         string             `json:"preset,omitempty"`
 	Batch          string             `json:"batch,omitempty"`
 	Source         TaskSource         `json:"source,omitempty"`
 	Error          string             `json:"error,omitempty"`
@@ -50,10 +84,14 @@ type Task struct {
 	CreatedAt      int64              `json:"createdAt"`
 	Progress       float64            `json:"progress"`
 	Priority       uint               `json:"priority"`
+	Weight         uint               `json:"weight"`
 	StartedAt      int64              `json:"startedAt,omitempty"`
 	FinishedAt     int64              `json:"finishedAt,omitempty"`
 	Remaining      float64            `json:"remaining"`
 	UpdatedAt      int64              `json:"updatedAt"`
+	Attempt        uint               `json:"attempt"`
+	RetryAt        int64              `json:"retryAt,omitempty"`
+	ScheduledAt    int64              `json:"scheduledAt,omitempty"`
 }
 
 type MetadataMap map[string]any
@@ -0,0 +1,30 @@
+package dto
+
+import "goyave.dev/goyave/v5/util/typeutil"
+
+// TaskFilter narrows down and sorts a task listing; empty fields are ignored
+type TaskFilter struct {
+	Page         typeutil.Undefined[int] `json:"page"`
+	PerPage      typeutil.Undefined[int] `json:"perPage"`
+	Status       []TaskStatus            `json:"status"`
+	Source       []TaskSource            `json:"source"`
+	Metadata     []string                `json:"metadata"` // key:value pairs, nested keys are separated by dots
+	Batch        string                  `json:"batch"`
+	Preset       string                  `json:"preset"`
+	Client       string                  `json:"client"`
+	Watchfolder  string                  `json:"watchfolder"` // uuid of the watchfolder that created the tasks
+	Search       string                  `json:"search"`      // case-insensitive substring of the name or the raw input file
+	Sort         string                  `json:"sort"`
+	Order        string                  `json:"order"`
+	CreatedFrom  int64                   `json:"createdFrom"`
+	CreatedTo    int64                   `json:"createdTo"`
+	FinishedFrom int64                   `json:"finishedFrom"`
+	FinishedTo   int64                   `json:"finishedTo"`
+}
+
+// HasCriteria reports whether the filter narrows down the tasks; paging and sorting are no criteria
+func (f *TaskFilter) HasCriteria() bool {
+	return len(f.Status) > 0 || len(f.Source) > 0 || len(f.Metadata) > 0 ||
+		f.Batch != "" || f.Preset != "" || f.Client != "" || f.Watchfolder != "" || f.Search != "" ||
+		f.CreatedFrom != 0 || f.CreatedTo != 0 || f.FinishedFrom != 0 || f.FinishedTo != 0
+}
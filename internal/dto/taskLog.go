@@ -0,0 +1,17 @@
+package dto
+
+type TaskLogSource string
+
+const (
+	TaskLogFFmpeg         TaskLogSource = "ffmpeg"
+	TaskLogPreProcessing  TaskLogSource = "preProcessing"
+	TaskLogPostProcessing TaskLogSource = "postProcessing"
+	TaskLogProbe          TaskLogSource = "probe"
+)
+
+type TaskLog struct {
+	Task      string        `json:"task"`
+	Source    TaskLogSource `json:"source"`
+	Line      string        `json:"line"`
+	CreatedAt int64         `json:"createdAt"`
+}
@@ -2,6 +2,8 @@ package dto
 
 import (
 	"time"
+
+	"goyave.dev/goyave/v5/util/typeutil"
 )
 
 type WebhookEvent string
@@ -25,19 +27,37 @@ const (
 	WatchfolderCreated WebhookEvent = "watchfolder.created"
 	WatchfolderUpdated WebhookEvent = "watchfolder.updated"
 	WatchfolderDeleted WebhookEvent = "watchfolder.deleted"
+
+	ScheduleCreated WebhookEvent = "schedule.created"
+	ScheduleUpdated WebhookEvent = "schedule.updated"
+	ScheduleDeleted WebhookEvent = "schedule.deleted"
+
+	ClientOffline WebhookEvent = "client.offline"
 )
 
 type NewWebhook struct {
-	Event WebhookEvent `json:"event"`
-	URL   string       `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Event       WebhookEvent      `json:"event"`
+	URL         string            `json:"url"`
+	Secret      string            `json:"secret,omitempty"`      // write-only, signs the payload using HMAC-SHA256
+	Template    string            `json:"template,omitempty"`    // go template rendering the body using ".event" and ".data"
+	ContentType string            `json:"contentType,omitempty"` // defaults to application/json
+	Timeout     uint              `json:"timeout,omitempty"`     // seconds per attempt, defaults to 10
+	MaxAttempts uint              `json:"maxAttempts,omitempty"` // attempts before the delivery is dead-lettered, defaults to 5
 }
 
 type Webhook struct {
-	CreatedAt time.Time    `json:"createdAt"`
-	UpdatedAt time.Time    `json:"updatedAt"`
-	Event     WebhookEvent `json:"event"`
-	URL       string       `json:"url"`
-	UUID      string       `json:"uuid"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Event       WebhookEvent      `json:"event"`
+	URL         string            `json:"url"`
+	UUID        string            `json:"uuid"`
+	Template    string            `json:"template,omitempty"`
+	ContentType string            `json:"contentType,omitempty"`
+	Timeout     uint              `json:"timeout,omitempty"`
+	MaxAttempts uint              `json:"maxAttempts,omitempty"`
+	Signed      bool              `json:"signed"`
 }
 
 type WebhookExecution struct {
@@ -46,8 +66,39 @@ type WebhookExecution struct {
 	Request   *WebhookRequest  `json:"request"`
 	Response  *WebhookResponse `json:"response"`
 	UUID      string           `json:"uuid"`
+	Webhook   string           `json:"webhook,omitempty"` // uuid of the global webhook, empty for direct webhooks
+	Task      string           `json:"task,omitempty"`    // uuid of the task the event was fired for
+	Delivery  string           `json:"delivery,omitempty"`
 	Event     WebhookEvent     `json:"event"`
 	URL       string           `json:"url"`
+	Error     string           `json:"error,omitempty"` // set if no response was received
+	Duration  int64            `json:"duration"`        // milliseconds until the response (or error) was received
+	Attempt   uint             `json:"attempt,omitempty"`
+}
+
+// WebhookExecutionFilter narrows down an execution listing; empty fields are ignored
+type WebhookExecutionFilter struct {
+	Page        typeutil.Undefined[int] `json:"page"`
+	PerPage     typeutil.Undefined[int] `json:"perPage"`
+	Event       []WebhookEvent          `json:"event"`
+	Webhook     string                  `json:"webhook"`
+	Task        string                  `json:"task"`
+	StatusFrom  int                     `json:"statusFrom"` // inclusive response status range, only executions with a response match
+	StatusTo    int                     `json:"statusTo"`
+	CreatedFrom int64                   `json:"createdFrom"`
+	CreatedTo   int64                   `json:"createdTo"`
+}
+
+// WebhookStats aggregates the recorded executions of a webhook; executions are successful on a 2xx response
+type WebhookStats struct {
+	LastFailure *WebhookExecution `json:"lastFailure"`
+	Webhook     string            `json:"webhook"`
+	Executions  int64             `json:"executions"`
+	Successful  int64             `json:"successful"`
+	Failed      int64             `json:"failed"`
+	SuccessRate float64           `json:"successRate"` // between 0 and 1
+	LatencyP50  int64             `json:"latencyP50"`  // milliseconds
+	LatencyP95  int64             `json:"latencyP95"`  // milliseconds
 }
 
 type WebhookRequest struct {
@@ -62,3 +113,72 @@ type WebhookResponse struct {
 }
 
 type DirectWebhooks []NewWebhook
+
+// MaskedHeader replaces the values of custom webhook headers everywhere but in the outgoing request
+const MaskedHeader = "********"
+
+// MaskHeaders returns a copy of the headers with masked values
+func MaskHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(headers))
+	for key := range headers {
+		masked[key] = MaskedHeader
+	}
+	return masked
+}
+
+// KeepHeaders replaces masked header values by the previous values of the same header; masked headers without previous value are removed
+func KeepHeaders(headers map[string]string, previous map[string]string) {
+	for key, value := range headers {
+		if value != MaskedHeader {
+			continue
+		}
+		if p, ok := previous[key]; ok {
+			headers[key] = p
+		} else {
+			delete(headers, key)
+		}
+	}
+}
+
+// Redacted returns a copy of the webhooks without their secrets and with masked headers
+func (w *DirectWebhooks) Redacted() *DirectWebhooks {
+	if w == nil {
+		return nil
+	}
+	redacted := make(DirectWebhooks, len(*w))
+	for i, webhook := range *w {
+		webhook.Secret = ""
+		webhook.Headers = MaskHeaders(webhook.Headers)
+		redacted[i] = webhook
+	}
+	return &redacted
+}
+
+// KeepSecrets sets the secret of webhooks without one and restores masked header values from the previous webhook with the same event and url
+func (w *DirectWebhooks) KeepSecrets(previous *DirectWebhooks) {
+	if w == nil {
+		return
+	}
+	for i, webhook := range *w {
+		var match *NewWebhook
+		if previous != nil {
+			for j, p := range *previous {
+				if p.Event == webhook.Event && p.URL == webhook.URL {
+					match = &(*previous)[j]
+					break
+				}
+			}
+		}
+		if match == nil {
+			KeepHeaders(webhook.Headers, nil)
+			continue
+		}
+		if webhook.Secret == "" {
+			(*w)[i].Secret = match.Secret
+		}
+		KeepHeaders(webhook.Headers, match.Headers)
+	}
+}
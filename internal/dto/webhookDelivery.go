@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"goyave.dev/goyave/v5/util/typeutil"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending    WebhookDeliveryStatus = "PENDING"
+	DeliveryDelivering WebhookDeliveryStatus = "DELIVERING"
+	DeliveryDeadLetter WebhookDeliveryStatus = "DEAD_LETTER"
+)
+
+// WebhookDelivery is a queued outbound webhook call; successfully delivered calls are removed from the queue
+type WebhookDelivery struct {
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+	Headers        map[string]string     `json:"headers,omitempty"`
+	UUID           string                `json:"uuid"`
+	Webhook        string                `json:"webhook,omitempty"` // uuid of the global webhook, empty for direct webhooks
+	Task           string                `json:"task,omitempty"`    // uuid of the task the event was fired for
+	Event          WebhookEvent          `json:"event"`
+	URL            string                `json:"url"`
+	Body           string                `json:"body"`
+	ContentType    string                `json:"contentType"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Error          string                `json:"error,omitempty"`
+	NextAttemptAt  int64                 `json:"nextAttemptAt"`
+	ResponseStatus int                   `json:"responseStatus,omitempty"`
+	Attempts       uint                  `json:"attempts"`
+	MaxAttempts    uint                  `json:"maxAttempts"`
+	Timeout        uint                  `json:"timeout"`
+}
+
+type WebhookDeliveryFilter struct {
+	Page    typeutil.Undefined[int] `json:"page"`
+	PerPage typeutil.Undefined[int] `json:"perPage"`
+	Status  []WebhookDeliveryStatus `json:"status"`
+}
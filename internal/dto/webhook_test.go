@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectWebhooksMaskedHeaders(t *testing.T) {
+	previous := &DirectWebhooks{{Event: TaskCreated, URL: "https://example.com", Secret: "s3cret", Headers: map[string]string{"Authorization": "Bearer token"}}}
+
+	redacted := previous.Redacted()
+	assert.Equal(t, "", (*redacted)[0].Secret)
+	assert.Equal(t, MaskedHeader, (*redacted)[0].Headers["Authorization"])
+	assert.Equal(t, "Bearer token", (*previous)[0].Headers["Authorization"], "the original is unchanged")
+
+	// masked values are restored, changed values are kept and masked values without previous value are removed
+	updated := &DirectWebhooks{
+		{Event: TaskCreated, URL: "https://example.com", Headers: map[string]string{"Authorization": MaskedHeader, "X-Unknown": MaskedHeader, "X-Team": "media"}},
+		{Event: TaskUpdated, URL: "https://example.com", Headers: map[string]string{"Authorization": MaskedHeader}},
+	}
+	updated.KeepSecrets(previous)
+	assert.Equal(t, "s3cret", (*updated)[0].Secret)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token", "X-Team": "media"}, (*updated)[0].Headers)
+	assert.Empty(t, (*updated)[1].Headers)
+}
@@ -16,7 +16,9 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/service"
 	"github.com/welovemedia/ffmate/v2/internal/service/client"
 	"github.com/welovemedia/ffmate/v2/internal/service/ffmpeg"
+	"github.com/welovemedia/ffmate/v2/internal/service/janitor"
 	"github.com/welovemedia/ffmate/v2/internal/service/preset"
+	"github.com/welovemedia/ffmate/v2/internal/service/schedule"
 	"github.com/welovemedia/ffmate/v2/internal/service/settings"
 	"github.com/welovemedia/ffmate/v2/internal/service/task"
 	"github.com/welovemedia/ffmate/v2/internal/service/telemetry"
@@ -77,23 +79,29 @@ func Init(options goyave.Options) {
 	presetRepository := (&repository.Preset{DB: server.DB()}).Setup()
 	webhookRepository := (&repository.Webhook{DB: server.DB()}).Setup()
 	webhookExecutionRepository := (&repository.WebhookExecution{DB: server.DB()}).Setup()
+	webhookDeliveryRepository := (&repository.WebhookDelivery{DB: server.DB()}).Setup()
 	watchfolderRepository := (&repository.Watchfolder{DB: server.DB()}).Setup()
+	scheduleRepository := (&repository.Schedule{DB: server.DB()}).Setup()
 	taskRepository := (&repository.Task{DB: server.DB()}).Setup()
+	taskLogRepository := (&repository.TaskLog{DB: server.DB()}).Setup()
 	settingRepository := (&repository.Settings{DB: server.DB()}).Setup()
 	clientRepository := (&repository.Client{DB: server.DB()}).Setup()
+	janitorRepository := &repository.Janitor{DB: server.DB()}
 
 	// setup and register services
 	telemetrySvc := telemetry.NewService(server.Config(), server.DB())
 	ffmpegSvc := ffmpeg.NewService()
 	updateSvc := update.NewService(server.Config().GetString("app.version"))
 	websocketSvc := websocket.NewService(server.DB())
-	webhookSvc := webhook.NewService(webhookRepository, webhookExecutionRepository, server.Config(), websocketSvc)
+	webhookSvc := webhook.NewService(webhookRepository, webhookExecutionRepository, webhookDeliveryRepository, server.Config(), websocketSvc).ProcessQueue()
 	presetSvc := preset.NewService(presetRepository, webhookSvc, websocketSvc)
-	taskSvc := task.NewService(taskRepository, presetSvc, webhookSvc, websocketSvc, ffmpegSvc).ProcessQueue()
+	taskSvc := task.NewService(taskRepository, taskLogRepository, presetSvc, webhookSvc, websocketSvc, ffmpegSvc).ProcessQueue()
 	traySvc := tray.NewService(server, taskSvc, updateSvc)
 	watchfolderSvc := watchfolder.NewService(watchfolderRepository, webhookSvc, websocketSvc, taskSvc)
-	settingSvc := settings.NewService(settingRepository)
-	clientSvc := client.NewService(clientRepository, server.Config().GetString("app.version"), websocketSvc)
+	scheduleSvc := schedule.NewService(scheduleRepository, webhookSvc, websocketSvc, taskSvc)
+	settingSvc := settings.NewService(settingRepository, websocketSvc)
+	janitorSvc := janitor.NewService(janitorRepository)
+	clientSvc := client.NewService(clientRepository, server.Config().GetString("app.version"), websocketSvc, webhookSvc, taskSvc)
 	for name, svc := range map[string]goyave.Service{
 		service.Tray:        traySvc,
 		service.Update:      updateSvc,
@@ -103,8 +111,10 @@ func Init(options goyave.Options) {
 		service.Webhook:     webhookSvc,
 		service.Preset:      presetSvc,
 		service.Watchfolder: watchfolderSvc,
+		service.Schedule:    scheduleSvc,
 		service.Task:        taskSvc,
 		service.Settings:    settingSvc,
+		service.Janitor:     janitorSvc,
 		service.Client:      clientSvc,
 	} {
 		server.RegisterService(svc)
@@ -150,9 +160,20 @@ func Init(options goyave.Options) {
 		})
 	}
 
+	// finish or requeue running tasks on shutdown
+	server.RegisterShutdownHook(func(*goyave.Server) {
+		taskSvc.Shutdown(time.Duration(cfg.GetInt("ffmate.shutdownTimeout")) * time.Second)
+	})
+
 	// start watchfolder processor
 	watchfolderSvc.Process()
 
+	// start schedule processor
+	scheduleSvc.Process()
+
+	// start janitor
+	janitorSvc.Process()
+
 	// enable tray
 	if cfg.GetBool("ffmate.isTray") {
 		traySvc.Run()
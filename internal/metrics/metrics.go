@@ -5,30 +5,58 @@ import "github.com/prometheus/client_golang/prometheus"
 var namespace = "ffmate"
 
 var gauges = map[string]prometheus.Gauge{
-	"batch.created":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_created", Help: "Number of created batches"}),
-	"batch.finished": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_finished", Help: "Number of finished batches"}),
+	"batch.created":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_created", Help: "Number of created batches"}),
+	"batch.finished":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_finished", Help: "Number of finished batches"}),
+	"batch.canceled":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_canceled", Help: "Number of canceled batches"}),
+	"batch.restarted": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_restarted", Help: "Number of restarted batches"}),
+	"batch.deleted":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "batch_deleted", Help: "Number of deleted batches"}),
+
+	"client.offline": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "client_offline", Help: "Number of clients marked as offline"}),
+
+	"workflow.created": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "workflow_created", Help: "Number of created workflows"}),
 
 	"task.created":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_created", Help: "Number of created tasks"}),
 	"task.deleted":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_deleted", Help: "Number of deleted tasks"}),
 	"task.updated":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_updated", Help: "Number of updated tasks"}),
 	"task.canceled":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_canceled", Help: "Number of canceled tasks"}),
 	"task.restarted": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_restarted", Help: "Number of restarted tasks"}),
+	"task.bulk":      prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_bulk", Help: "Number of bulk task operations"}),
+	"task.retried":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_retried", Help: "Number of automatically retried tasks"}),
+	"task.requeued":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_requeued", Help: "Number of tasks requeued due to a shutdown or restart"}),
+	"task.paused":    prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_paused", Help: "Number of paused tasks"}),
+	"task.resumed":   prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "task_resumed", Help: "Number of resumed tasks"}),
 
-	"preset.created": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_created", Help: "Number of created presets"}),
-	"preset.updated": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_updated", Help: "Number of updated presets"}),
-	"preset.deleted": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_deleted", Help: "Number of deleted presets"}),
+	"preset.created":     prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_created", Help: "Number of created presets"}),
+	"preset.updated":     prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_updated", Help: "Number of updated presets"}),
+	"preset.deleted":     prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_deleted", Help: "Number of deleted presets"}),
+	"preset.ruleMatched": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "preset_rule_matched", Help: "Number of tasks whose preset was selected by a preset rule"}),
 
 	"webhook.created":         prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_created", Help: "Number of created webhooks"}),
 	"webhook.executed":        prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_executed", Help: "Number of executed webhooks"}),
 	"webhook.executed.direct": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_executed_direct", Help: "Number of directly executed webhooks"}),
 	"webhook.updated":         prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_updated", Help: "Number of updated webhooks"}),
 	"webhook.deleted":         prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_deleted", Help: "Number of deleted webhooks"}),
+	"webhook.retried":         prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_retried", Help: "Number of failed webhook deliveries scheduled for retry"}),
+	"webhook.deadLettered":    prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_dead_lettered", Help: "Number of webhook deliveries that exhausted their attempts"}),
+	"webhook.redelivered":     prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_redelivered", Help: "Number of manually redelivered webhook executions"}),
+	"webhook.tested":          prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "webhook_tested", Help: "Number of webhook test events"}),
 
 	"watchfolder.created":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "watchfolder_created", Help: "Number of created watchfolders"}),
 	"watchfolder.executed": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "watchfolder_executed", Help: "Number of executed watchfolders"}),
 	"watchfolder.updated":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "watchfolder_updated", Help: "Number of updated watchfolder"}),
 	"watchfolder.deleted":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "watchfolder_deleted", Help: "Number of deleted watchfolders"}),
 
+	"schedule.created":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "schedule_created", Help: "Number of created schedules"}),
+	"schedule.executed": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "schedule_executed", Help: "Number of executed schedules"}),
+	"schedule.updated":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "schedule_updated", Help: "Number of updated schedules"}),
+	"schedule.deleted":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "schedule_deleted", Help: "Number of deleted schedules"}),
+
+	"settings.updated": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "settings_updated", Help: "Number of settings updates"}),
+
+	"janitor.purged": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "janitor_purged", Help: "Number of rows purged by the janitor"}),
+
+	"probe.executed": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "probe_executed", Help: "Number of files probed via the api"}),
+
 	"websocket.broadcast":  prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "websocket_broadcast", Help: "Number of broadcasted messages"}),
 	"websocket.connect":    prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "websocket_connect", Help: "Number of websocket connections"}),
 	"websocket.disconnect": prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "websocket_disconnect", Help: "Number of websocket disconnections"}),
@@ -41,6 +69,7 @@ var gaugeVecLabels = map[string][]string{
 	"task.preProcessing":  {"sidecarPath", "scriptPath"},
 	"task.postProcessing": {"sidecarPath", "scriptPath"},
 	"preset.global":       {"name"},
+	"queue.updated":       {"status"},
 }
 var gaugesVec = map[string]*prometheus.GaugeVec{
 	"rest.api": prometheus.NewGaugeVec(
@@ -59,6 +88,14 @@ var gaugesVec = map[string]*prometheus.GaugeVec{
 		},
 		gaugeVecLabels["umami"],
 	),
+	"queue.updated": prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_updated",
+			Help:      "Number of queue status changes",
+		},
+		gaugeVecLabels["queue.updated"],
+	),
 	"task.preProcessing": prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -11,6 +11,7 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/debug"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/webhook"
 	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
 )
 
@@ -18,23 +19,44 @@ type Repository interface {
 	List(page int, perPage int) (*[]model.Client, int64, error)
 	Add(client *model.Client) (*model.Client, error)
 	First() (*model.Client, error)
+	FirstByIdentifier(identifier string) (*model.Client, error)
+	ListStale(lastSeen int64) (*[]model.Client, error)
+	MarkOffline(identifier string) (bool, error)
+}
+
+type TaskService interface {
+	ReclaimTasks(identifier string)
+	RunningTasks() int
 }
 
 type Service struct {
 	repository       Repository
 	websocketService *websocket.Service
+	webhookService   *webhook.Service
+	taskService      TaskService
 	version          string
 }
 
-func NewService(repository *repository.Client, version string, websocketService *websocket.Service) *Service {
+func NewService(repository *repository.Client, version string, websocketService *websocket.Service, webhookService *webhook.Service, taskService TaskService) *Service {
 	s := &Service{
 		repository:       repository,
 		version:          version,
 		websocketService: websocketService,
+		webhookService:   webhookService,
+		taskService:      taskService,
 	}
 
+	// receive queue updates from other cluster members
+	websocketService.HandleCluster(websocket.QueueUpdate, s.handleQueueUpdate)
+
 	// periodically update client info
 	s.UpdateClientInfo()
+	go s.watchDrain()
+
+	// detect clients that stopped updating their info
+	if cfg.GetBool("ffmate.isCluster") {
+		go s.reapStaleClients()
+	}
 
 	return s
 }
@@ -57,6 +79,8 @@ func (s *Service) save(newClient *dto.NewClient) (*model.Client, error) {
 		Arch:     runtime.GOARCH,
 		Version:  s.version,
 		FFMpeg:   cfg.GetString("ffmate.ffmpeg"),
+		Labels:   cfg.GetOrDefault("ffmate.labels", []string{}),
+		Queue:    QueueStatus(),
 		LastSeen: time.Now().UnixMilli(),
 	}
 
@@ -72,6 +96,10 @@ func (s *Service) first() (*model.Client, error) {
 
 var localClient *dto.NewClient
 
+// HeartbeatInterval is the interval in which clients update their info; clients not seen
+// within the (larger) offline grace period are considered offline
+const HeartbeatInterval = 15 * time.Second
+
 func (s *Service) UpdateClientInfo() {
 	localClient = &dto.NewClient{
 		Identifier: cfg.GetString("ffmate.identifier"),
@@ -88,13 +116,16 @@ func (s *Service) UpdateClientInfo() {
 	// save for telemetry
 	cfg.Set("ffmate.cluster", localClient.Cluster)
 
+	// keep pausing or draining across restarts
+	s.restoreQueueStatus()
+
 	// save client directly
 	s.saveClient(localClient)
 
 	// re-save client periodically
 	go func() {
 		for {
-			time.Sleep(15 * time.Second)
+			time.Sleep(HeartbeatInterval)
 			s.saveClient(localClient)
 		}
 	}()
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+// queueUpdate is sent to the cluster to change the queue status of one (or all if identifier is empty) clients
+type queueUpdate struct {
+	Identifier string          `json:"identifier"`
+	Status     dto.QueueStatus `json:"status"`
+}
+
+// QueueStatus returns whether this client currently picks up new tasks
+func QueueStatus() dto.QueueStatus {
+	return cfg.GetOrDefault("ffmate.queue", dto.QueueActive)
+}
+
+// SetQueueStatus changes the queue status of the client with the given identifier (or all clients of the cluster if empty)
+func (s *Service) SetQueueStatus(identifier string, status dto.QueueStatus) error {
+	if identifier != "" && identifier != localClient.Identifier {
+		if !cfg.GetBool("ffmate.isCluster") {
+			return errors.New("client for given identifier not found")
+		}
+		c, err := s.repository.FirstByIdentifier(identifier)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return errors.New("client for given identifier not found")
+		}
+		s.websocketService.NotifyCluster(websocket.QueueUpdate, &queueUpdate{Identifier: identifier, Status: status})
+		return nil
+	}
+
+	if identifier == "" {
+		s.websocketService.NotifyCluster(websocket.QueueUpdate, &queueUpdate{Status: status})
+	}
+	return s.applyQueueStatus(status)
+}
+
+// applyQueueStatus changes the queue status of this client; a failure to persist it is logged and returned rather than fatal
+func (s *Service) applyQueueStatus(status dto.QueueStatus) error {
+	cfg.Set("ffmate.queue", status)
+	if _, err := s.save(localClient); err != nil {
+		debug.Client.Error("failed to save queue status '%s': %v", status, err)
+		return err
+	}
+
+	metrics.GaugeVec("queue.updated").WithLabelValues(string(status)).Inc()
+	debug.Log.Info("changed queue status to '%s'", status)
+
+	s.checkDrained()
+	return nil
+}
+
+// restoreQueueStatus applies the queue status persisted by a previous run of this client
+func (s *Service) restoreQueueStatus() {
+	c, err := s.repository.FirstByIdentifier(localClient.Identifier)
+	if err != nil {
+		debug.Client.Error("failed to restore queue status: %v", err)
+		return
+	}
+	if c != nil && c.Queue != "" && c.Queue != dto.QueueActive {
+		cfg.Set("ffmate.queue", c.Queue)
+		debug.Log.Info("restored queue status '%s'", c.Queue)
+	}
+}
+
+func (s *Service) watchDrain() {
+	for {
+		time.Sleep(1 * time.Second)
+		s.checkDrained()
+	}
+}
+
+// checkDrained marks a draining queue as drained once all running tasks finished
+func (s *Service) checkDrained() {
+	if QueueStatus() != dto.QueueDraining || s.taskService.RunningTasks() > 0 {
+		return
+	}
+	_ = s.applyQueueStatus(dto.QueueDrained)
+}
+
+func (s *Service) handleQueueUpdate(payload any) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	var update queueUpdate
+	if err := json.Unmarshal(b, &update); err != nil {
+		debug.Client.Error("failed to parse queue update: %v", err)
+		return
+	}
+	if update.Identifier != "" && update.Identifier != localClient.Identifier {
+		return
+	}
+	_ = s.applyQueueStatus(update.Status)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+func (s *Service) reapStaleClients() {
+	for {
+		time.Sleep(HeartbeatInterval)
+		s.ReapStaleClients()
+	}
+}
+
+// ReapStaleClients marks clients that were not seen within the grace period as offline and reclaims their tasks
+func (s *Service) ReapStaleClients() {
+	gracePeriod := time.Duration(cfg.GetOrDefault("ffmate.clientOfflineAfter", 60)) * time.Second
+	clients, err := s.repository.ListStale(time.Now().Add(-gracePeriod).UnixMilli())
+	if err != nil {
+		debug.Client.Error("failed to receive stale clients: %v", err)
+		return
+	}
+
+	for _, c := range *clients {
+		if c.Identifier == localClient.Identifier {
+			continue
+		}
+
+		// only one cluster member wins marking the client offline
+		marked, err := s.repository.MarkOffline(c.Identifier)
+		if err != nil {
+			debug.Client.Error("failed to mark client offline (identifier: %s): %v", c.Identifier, err)
+			continue
+		}
+		if !marked {
+			continue
+		}
+		c.Offline = true
+
+		debug.Log.Warn("client went offline (identifier: %s, lastSeen: %s)", c.Identifier, time.UnixMilli(c.LastSeen).Format(time.RFC3339))
+
+		s.taskService.ReclaimTasks(c.Identifier)
+
+		metrics.Gauge("client.offline").Inc()
+		s.webhookService.Fire(dto.ClientOffline, c.ToDTO())
+		s.websocketService.Broadcast(websocket.ClientUpdated, c.ToDTO())
+	}
+}
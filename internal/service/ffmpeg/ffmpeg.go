@@ -6,12 +6,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os/exec"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 	"unicode"
 
 	"github.com/mattn/go-shellwords"
@@ -40,13 +44,14 @@ type ExecutionRequest struct {
 	Ctx        context.Context
 	Task       *model.Task
 	UpdateFunc func(progress float64, remaining float64)
+	LogFunc    func(line string)
 	Command    string
 }
 
-// Execute runs the ffmpeg command, provides progress updates, and checks the result
-func (s *Service) Execute(request *ExecutionRequest) error {
-	commands := strings.Split(request.Command, "&&")
-	for index, cmdStr := range commands {
+// ParseCommand splits the command into its (&& separated) commands and their arguments
+func (s *Service) ParseCommand(command string) ([][]string, error) {
+	var commands [][]string
+	for _, cmdStr := range strings.Split(command, "&&") {
 		cmdStr = strings.TrimSpace(cmdStr)
 		var args []string
 		var err error
@@ -56,10 +61,28 @@ func (s *Service) Execute(request *ExecutionRequest) error {
 			args, err = shellwords.NewParser().Parse(cmdStr)
 		}
 		if err != nil {
-			return fmt.Errorf("FFMPEG - failed to parse command: %v", err)
+			return nil, fmt.Errorf("FFMPEG - failed to parse command: %v", err)
 		}
+		commands = append(commands, args)
+	}
+	return commands, nil
+}
+
+// Execute runs the ffmpeg command, provides progress updates, and checks the result
+func (s *Service) Execute(request *ExecutionRequest) error {
+	commands, err := s.ParseCommand(request.Command)
+	if err != nil {
+		return err
+	}
+
+	proc := &process{}
+	processes.Store(request.Task.UUID, proc)
+	defer processes.Delete(request.Task.UUID)
+
+	for index, args := range commands {
+		hasStatsPeriod := slices.Contains(args, "-stats_period")
 		args = append(args, "-progress", "pipe:2")
-		if !strings.Contains(cmdStr, "-stats_period") {
+		if !hasStatsPeriod {
 			args = append(args, "-stats_period", "1")
 		}
 		var cmd *exec.Cmd
@@ -77,9 +100,23 @@ func (s *Service) Execute(request *ExecutionRequest) error {
 			return fmt.Errorf("FFMPEG - failed to get stderr pipe: %v", err)
 		}
 
+		stdinPipe, err := cmd.StdinPipe()
+		if err != nil {
+			return fmt.Errorf("FFMPEG - failed to get stdin pipe: %v", err)
+		}
+
+		// on cancellation ask ffmpeg to quit, then terminate and finally kill it
+		exited := make(chan struct{})
+		cmd.Cancel = func() error {
+			s.terminate(cmd, stdinPipe, exited, request.Task.UUID)
+			return nil
+		}
+		cmd.WaitDelay = 2 * TerminateGracePeriod
+
 		if err := cmd.Start(); err != nil {
 			return fmt.Errorf("FFMPEG - failed to start ffmpeg: %v", err)
 		}
+		proc.start(cmd, request.Task.UUID)
 
 		reDuration := regexp.MustCompile(`Duration: (\d+:\d+:\d+\.\d+)`)
 
@@ -87,11 +124,14 @@ func (s *Service) Execute(request *ExecutionRequest) error {
 		for scanner.Scan() {
 			line := scanner.Text()
 			stderrBuf.WriteString(line + "\n")
+			if request.LogFunc != nil && !s.isProgressLine(line) {
+				request.LogFunc(line)
+			}
 			if match := reDuration.FindStringSubmatch(line); match != nil {
 				durationStr := match[1]
 				duration = s.parseDuration(durationStr)
 			}
-			if progress := s.parseFFmpegOutput(line, duration); progress != nil {
+			if progress := s.parseFFmpegOutput(line, duration); progress != nil && !s.IsPaused(request.Task.UUID) {
 				p := math.Min(100, math.Round((progress.Time/duration*100)*100)/100)
 				debug.FFmpeg.Debug("progress: %f %+v (uuid: %s)", p, progress, request.Task.UUID)
 				remainingTime, err := progress.EstimateRemainingTime(duration)
@@ -107,6 +147,8 @@ func (s *Service) Execute(request *ExecutionRequest) error {
 		}
 
 		err = cmd.Wait()
+		close(exited)
+		proc.stop()
 		stderr := stderrBuf.String()
 		if err != nil {
 			return errors.New(stderr)
@@ -115,6 +157,30 @@ func (s *Service) Execute(request *ExecutionRequest) error {
 	return nil
 }
 
+// TerminateGracePeriod is the time a canceled process is given to exit before it is terminated (and killed after twice the time)
+var TerminateGracePeriod = 5 * time.Second
+
+// terminate sends 'q' to ffmpeg and falls back to SIGTERM if it did not exit within the grace period
+func (s *Service) terminate(cmd *exec.Cmd, stdin io.WriteCloser, exited <-chan struct{}, uuid string) {
+	// a suspended process would neither read stdin nor handle SIGTERM
+	_ = s.Resume(uuid)
+
+	debug.FFmpeg.Debug("asking ffmpeg to quit (uuid: %s)", uuid)
+	_, _ = stdin.Write([]byte("q"))
+	_ = stdin.Close()
+
+	go func() {
+		select {
+		case <-exited:
+		case <-time.After(TerminateGracePeriod):
+			debug.FFmpeg.Debug("ffmpeg did not quit, terminating (uuid: %s)", uuid)
+			if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+				_ = cmd.Process.Kill()
+			}
+		}
+	}()
+}
+
 // EstimateRemainingTime calculates the estimated remaining time based on the current progress and speed.
 func (p *FFmpegProgress) EstimateRemainingTime(duration float64) (float64, error) {
 	speed, err := p.parseSpeed(p.Speed)
@@ -186,6 +252,16 @@ func (s *Service) parseFFmpegOutput(line string, duration float64) *FFmpegProgre
 	return progress
 }
 
+// isProgressLine reports whether the line is part of the periodic progress/stats output
+func (s *Service) isProgressLine(line string) bool {
+	if strings.HasPrefix(line, "frame=") || strings.HasPrefix(line, "size=") {
+		return true
+	}
+	return reProgressKeyValue.MatchString(line)
+}
+
+var reProgressKeyValue = regexp.MustCompile(`^\w+=\S*$`)
+
 func (s *Service) shellwordsUnicodeSafe(input string) ([]string, error) {
 	var args []string
 	var current strings.Builder
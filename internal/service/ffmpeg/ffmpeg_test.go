@@ -0,0 +1,100 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+)
+
+func TestExecuteTerminatesOnCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	// fake ffmpeg ignoring 'q' on stdin so it has to be terminated
+	bin := filepath.Join(t.TempDir(), "ffmpeg")
+	assert.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\nwhile true; do sleep 0.1; done\n"), 0755))
+	cfg.Set("ffmate.ffmpeg", bin)
+
+	gracePeriod := TerminateGracePeriod
+	TerminateGracePeriod = 100 * time.Millisecond
+	defer func() { TerminateGracePeriod = gracePeriod }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	done := make(chan error)
+	go func() {
+		done <- NewService().Execute(&ExecutionRequest{
+			Ctx:        ctx,
+			Task:       &model.Task{UUID: "test"},
+			Command:    "-i input.mp4 output.mp4",
+			UpdateFunc: func(_ float64, _ float64) {},
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ffmpeg process was not terminated (timeout)")
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires posix signals")
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill() // nolint:errcheck
+	processes.Store("test", &process{cmd: cmd})
+	defer processes.Delete("test")
+
+	svc := NewService()
+	assert.NoError(t, svc.Pause("test"))
+	assert.True(t, svc.IsPaused("test"))
+	assert.NoError(t, svc.Resume("test"))
+	assert.False(t, svc.IsPaused("test"))
+	assert.Error(t, svc.Pause("unknown"))
+}
+
+func TestPauseCarriesOverToNextCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires posix signals")
+	}
+
+	// paused between two chained commands
+	proc := &process{}
+	processes.Store("test", proc)
+	defer processes.Delete("test")
+
+	svc := NewService()
+	assert.NoError(t, svc.Pause("test"))
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill() // nolint:errcheck
+	proc.start(cmd, "test")
+
+	assert.True(t, svc.IsPaused("test"))
+	if _, err := os.Stat("/proc"); err == nil {
+		assert.Eventually(t, func() bool {
+			stat, _ := os.ReadFile(fmt.Sprintf("/proc/%d/stat", cmd.Process.Pid))
+			return strings.Contains(string(stat), ") T ")
+		}, time.Second, 10*time.Millisecond)
+	}
+	assert.NoError(t, svc.Resume("test"))
+	assert.False(t, svc.IsPaused("test"))
+}
@@ -0,0 +1,154 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+type ffprobeOutput struct {
+	Format struct {
+		Tags       map[string]string `json:"tags"`
+		Filename   string            `json:"filename"`
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		Size       string            `json:"size"`
+		BitRate    string            `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		Tags          map[string]string `json:"tags"`
+		CodecType     string            `json:"codec_type"`
+		CodecName     string            `json:"codec_name"`
+		Profile       string            `json:"profile"`
+		PixFmt        string            `json:"pix_fmt"`
+		ChannelLayout string            `json:"channel_layout"`
+		RFrameRate    string            `json:"r_frame_rate"`
+		SampleRate    string            `json:"sample_rate"`
+		Duration      string            `json:"duration"`
+		BitRate       string            `json:"bit_rate"`
+		Index         int               `json:"index"`
+		Width         int               `json:"width"`
+		Height        int               `json:"height"`
+		Channels      int               `json:"channels"`
+	} `json:"streams"`
+}
+
+// ffprobe returns the ffprobe binary, defaulting to the one next to the ffmpeg binary
+func ffprobe() string {
+	if bin := cfg.GetString("ffmate.ffprobe"); bin != "" {
+		return bin
+	}
+	if ffmpeg := cfg.GetString("ffmate.ffmpeg"); filepath.IsAbs(ffmpeg) {
+		sibling := filepath.Join(filepath.Dir(ffmpeg), strings.Replace(filepath.Base(ffmpeg), "ffmpeg", "ffprobe", 1))
+		if _, err := exec.LookPath(sibling); err == nil {
+			return sibling
+		}
+	}
+	return "ffprobe"
+}
+
+// Probe reads the format and stream information of the file using ffprobe; it is aborted after the configured probe timeout
+func (s *Service) Probe(ctx context.Context, path string) (*dto.Probe, error) {
+	if timeout := cfg.GetOrDefault("ffmate.probeTimeout", 30); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffprobe(), "-v", "error", "-print_format", "json", "-show_format", "-show_streams", path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("FFPROBE - timed out probing '%s'", path)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("FFPROBE - failed to probe '%s': %s", path, msg)
+		}
+		return nil, fmt.Errorf("FFPROBE - failed to probe '%s': %v", path, err)
+	}
+	return parseProbe(stdout.Bytes())
+}
+
+func parseProbe(data []byte) (*dto.Probe, error) {
+	var output ffprobeOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("FFPROBE - failed to parse output: %v", err)
+	}
+
+	probe := &dto.Probe{
+		Format: dto.ProbeFormat{
+			Tags:       output.Format.Tags,
+			Filename:   output.Format.Filename,
+			FormatName: output.Format.FormatName,
+			Duration:   parseFloat(output.Format.Duration),
+			Size:       parseInt(output.Format.Size),
+			BitRate:    parseInt(output.Format.BitRate),
+		},
+		Streams: []dto.ProbeStream{},
+	}
+
+	for _, stream := range output.Streams {
+		probe.Streams = append(probe.Streams, dto.ProbeStream{
+			CodecType:     stream.CodecType,
+			CodecName:     stream.CodecName,
+			Profile:       stream.Profile,
+			PixelFormat:   stream.PixFmt,
+			ChannelLayout: stream.ChannelLayout,
+			Language:      stream.Tags["language"],
+			Index:         stream.Index,
+			Width:         stream.Width,
+			Height:        stream.Height,
+			FrameRate:     parseFrameRate(stream.RFrameRate),
+			SampleRate:    int(parseInt(stream.SampleRate)),
+			Channels:      stream.Channels,
+			Duration:      parseFloat(stream.Duration),
+			BitRate:       parseInt(stream.BitRate),
+		})
+	}
+
+	for i := range probe.Streams {
+		switch stream := &probe.Streams[i]; {
+		case stream.CodecType == "video" && probe.Video == nil:
+			probe.Video = stream
+		case stream.CodecType == "audio" && probe.Audio == nil:
+			probe.Audio = stream
+		}
+	}
+
+	return probe, nil
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseInt(s string) int64 {
+	i, _ := strconv.ParseInt(s, 10, 64)
+	return i
+}
+
+// parseFrameRate parses rational frame rates like "30000/1001"
+func parseFrameRate(s string) float64 {
+	num, den, ok := strings.Cut(s, "/")
+	if !ok {
+		return parseFloat(s)
+	}
+	if d := parseFloat(den); d != 0 {
+		return math.Round(parseFloat(num)/d*1000) / 1000
+	}
+	return 0
+}
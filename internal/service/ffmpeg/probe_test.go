@@ -0,0 +1,57 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+)
+
+func TestParseProbe(t *testing.T) {
+	probe, err := parseProbe([]byte(`{
+		"streams": [
+			{"index": 0, "codec_type": "audio", "codec_name": "aac", "sample_rate": "48000", "channels": 2, "channel_layout": "stereo", "tags": {"language": "eng"}},
+			{"index": 1, "codec_type": "video", "codec_name": "h264", "profile": "High", "pix_fmt": "yuv420p", "width": 1920, "height": 1080, "r_frame_rate": "30000/1001", "bit_rate": "4000000"}
+		],
+		"format": {"filename": "input.mp4", "format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "12.500000", "size": "6250000", "bit_rate": "4000000", "tags": {"title": "test"}}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Len(t, probe.Streams, 2)
+	assert.Equal(t, "h264", probe.Video.CodecName)
+	assert.Equal(t, 1920, probe.Video.Width)
+	assert.Equal(t, 29.97, probe.Video.FrameRate)
+	assert.Equal(t, int64(4000000), probe.Video.BitRate)
+	assert.Equal(t, "aac", probe.Audio.CodecName)
+	assert.Equal(t, 48000, probe.Audio.SampleRate)
+	assert.Equal(t, "eng", probe.Audio.Language)
+	assert.Equal(t, 12.5, probe.Format.Duration)
+	assert.Equal(t, int64(6250000), probe.Format.Size)
+	assert.Equal(t, "test", probe.Format.Tags["title"])
+
+	_, err = parseProbe([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestProbeTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	// fake ffprobe hanging on the input
+	bin := filepath.Join(t.TempDir(), "ffprobe")
+	assert.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\nexec sleep 10\n"), 0755))
+	cfg.Set("ffmate.ffprobe", bin)
+	cfg.Set("ffmate.probeTimeout", 1)
+	defer cfg.Set("ffmate.ffprobe", "")
+
+	start := time.Now()
+	_, err := NewService().Probe(context.Background(), "input.mp4")
+	assert.ErrorContains(t, err, "timed out")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
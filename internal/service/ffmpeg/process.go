@@ -0,0 +1,94 @@
+package ffmpeg
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+)
+
+// process tracks the ffmpeg commands of a task; it outlives a single command so that
+// the paused state carries over to the next command of a chained (&&) command
+type process struct {
+	cmd    *exec.Cmd
+	paused bool
+	mu     sync.Mutex
+}
+
+var processes = sync.Map{}
+
+// start sets the running command and suspends it right away if the task has been paused
+func (p *process) start(cmd *exec.Cmd, uuid string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cmd = cmd
+	if !p.paused {
+		return
+	}
+	if err := stopProcess(cmd.Process); err != nil {
+		debug.FFmpeg.Error("failed to suspend next command of paused task (uuid: %s): %v", uuid, err)
+		p.paused = false
+	}
+}
+
+// stop clears the command once it exited
+func (p *process) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cmd = nil
+}
+
+// Pause suspends the ffmpeg process of the given task; between two chained commands the next command starts suspended
+func (s *Service) Pause(uuid string) error {
+	p, ok := processes.Load(uuid)
+	if !ok {
+		return errors.New("no running ffmpeg process for given uuid found")
+	}
+	proc := p.(*process)
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+	if proc.paused {
+		return nil
+	}
+	if proc.cmd != nil {
+		if err := stopProcess(proc.cmd.Process); err != nil {
+			return err
+		}
+	}
+	proc.paused = true
+	return nil
+}
+
+// Resume continues the suspended ffmpeg process of the given task
+func (s *Service) Resume(uuid string) error {
+	p, ok := processes.Load(uuid)
+	if !ok {
+		return errors.New("no running ffmpeg process for given uuid found")
+	}
+	proc := p.(*process)
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+	if !proc.paused {
+		return nil
+	}
+	if proc.cmd != nil {
+		if err := continueProcess(proc.cmd.Process); err != nil {
+			return err
+		}
+	}
+	proc.paused = false
+	return nil
+}
+
+// IsPaused reports whether the ffmpeg process of the given task is suspended
+func (s *Service) IsPaused(uuid string) bool {
+	p, ok := processes.Load(uuid)
+	if !ok {
+		return false
+	}
+	proc := p.(*process)
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+	return proc.paused
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package ffmpeg
+
+import (
+	"os"
+	"syscall"
+)
+
+func stopProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGSTOP)
+}
+
+func continueProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGCONT)
+}
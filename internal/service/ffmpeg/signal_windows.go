@@ -0,0 +1,16 @@
+package ffmpeg
+
+import (
+	"errors"
+	"os"
+)
+
+var errPauseUnsupported = errors.New("pausing running tasks is not supported on windows")
+
+func stopProcess(_ *os.Process) error {
+	return errPauseUnsupported
+}
+
+func continueProcess(_ *os.Process) error {
+	return errPauseUnsupported
+}
@@ -0,0 +1,106 @@
+package janitor
+
+import (
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/settings"
+)
+
+type Repository interface {
+	PurgeTasks(status dto.TaskStatus, finishedBefore int64, dryRun bool) (int64, int64, error)
+	PurgeDeleted(deletedBefore time.Time, dryRun bool) (int64, int64, error)
+	CapWebhookExecutions(keep int, dryRun bool) (int64, error)
+	PurgeDeadLetters(deadBefore time.Time, dryRun bool) (int64, error)
+}
+
+type Service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+// Run applies the retention settings; a dry run only reports what would be purged
+func (s *Service) Run(dryRun bool) (*dto.JanitorReport, error) {
+	report := &dto.JanitorReport{DryRun: dryRun}
+
+	retention := settings.Retention()
+	if retention == nil {
+		return report, nil
+	}
+
+	now := time.Now()
+	for status, days := range map[dto.TaskStatus]uint{
+		dto.DoneSuccessful: retention.DoneSuccessful,
+		dto.DoneError:      retention.DoneError,
+		dto.DoneCanceled:   retention.DoneCanceled,
+	} {
+		if days == 0 {
+			continue
+		}
+		tasks, logs, err := s.repository.PurgeTasks(status, now.AddDate(0, 0, -int(days)).UnixMilli(), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		report.Tasks += tasks
+		report.TaskLogs += logs
+	}
+
+	if retention.Deleted > 0 {
+		rows, logs, err := s.repository.PurgeDeleted(now.AddDate(0, 0, -int(retention.Deleted)), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		report.Deleted += rows
+		report.TaskLogs += logs
+	}
+
+	if retention.WebhookExecutions > 0 {
+		rows, err := s.repository.CapWebhookExecutions(int(retention.WebhookExecutions), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		report.WebhookExecutions += rows
+	}
+
+	if retention.DeadLetters > 0 {
+		rows, err := s.repository.PurgeDeadLetters(now.AddDate(0, 0, -int(retention.DeadLetters)), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		report.DeadLetters += rows
+	}
+
+	if !dryRun {
+		metrics.Gauge("janitor.purged").Add(float64(report.Tasks + report.TaskLogs + report.Deleted + report.WebhookExecutions + report.DeadLetters))
+	}
+
+	return report, nil
+}
+
+// Process runs the janitor on startup and every hour
+func (s *Service) Process() {
+	go func() {
+		for {
+			report, err := s.Run(false)
+			if err != nil {
+				debug.Log.Error("failed to run janitor: %v", err)
+			} else {
+				debug.Janitor.Debug("purged %d tasks, %d task logs, %d deleted rows, %d webhook executions and %d dead letters", report.Tasks, report.TaskLogs, report.Deleted, report.WebhookExecutions, report.DeadLetters)
+			}
+			next := time.Now().Truncate(time.Hour).Add(time.Hour)
+			time.Sleep(time.Until(next))
+		}
+	}()
+}
+
+func (s *Service) Name() string {
+	return service.Janitor
+}
@@ -20,6 +20,8 @@ type Repository interface {
 	First(uuid string) (*model.Preset, error)
 	Delete(preset *model.Preset) error
 	Count() (int64, error)
+
+	ListLimited() (*[]model.Preset, error)
 }
 
 type Service struct {
@@ -53,7 +55,28 @@ func (s *Service) List(page int, perPage int) (*[]model.Preset, int64, error) {
 	return s.repository.List(page, perPage)
 }
 
+// ConcurrencyLimits returns the maximum concurrent tasks per node of all presets defining a limit
+func (s *Service) ConcurrencyLimits() (map[string]uint, error) {
+	presets, err := s.repository.ListLimited()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]uint, len(*presets))
+	for _, preset := range *presets {
+		limits[preset.UUID] = preset.MaxConcurrent
+	}
+	return limits, nil
+}
+
 func (s *Service) Add(newPreset *dto.NewPreset) (*model.Preset, error) {
+	if err := s.validateRules(newPreset.Rules, newPreset.Command); err != nil {
+		return nil, err
+	}
+	if _, err := newPreset.Retry.Matcher(); err != nil {
+		return nil, err
+	}
+
 	preset := &model.Preset{
 		UUID:           uuid.NewString(),
 		Command:        newPreset.Command,
@@ -64,6 +87,12 @@ func (s *Service) Add(newPreset *dto.NewPreset) (*model.Preset, error) {
 		OutputFile:     newPreset.OutputFile,
 		PreProcessing:  newPreset.PreProcessing,
 		PostProcessing: newPreset.PostProcessing,
+		Retry:          newPreset.Retry,
+		Rules:          newPreset.Rules,
+		Labels:         newPreset.Labels,
+		Tags:           newPreset.Tags,
+		Weight:         newPreset.Weight,
+		MaxConcurrent:  newPreset.MaxConcurrent,
 	}
 	w, err := s.repository.Add(preset)
 	debug.Log.Info("created preset (uuid: %s)", w.UUID)
@@ -90,6 +119,13 @@ func (s *Service) Update(uuid string, newPreset *dto.NewPreset) (*model.Preset,
 		return nil, errors.New("preset for given uuid not found")
 	}
 
+	if err := s.validateRules(newPreset.Rules, newPreset.Command); err != nil {
+		return nil, err
+	}
+	if _, err := newPreset.Retry.Matcher(); err != nil {
+		return nil, err
+	}
+
 	w.Name = newPreset.Name
 	w.Description = newPreset.Description
 	w.Command = newPreset.Command
@@ -97,7 +133,14 @@ func (s *Service) Update(uuid string, newPreset *dto.NewPreset) (*model.Preset,
 	w.PostProcessing = newPreset.PostProcessing
 	w.OutputFile = newPreset.OutputFile
 	w.Priority = newPreset.Priority
+	newPreset.Webhooks.KeepSecrets(w.Webhooks)
 	w.Webhooks = newPreset.Webhooks
+	w.Retry = newPreset.Retry
+	w.Rules = newPreset.Rules
+	w.Labels = newPreset.Labels
+	w.Tags = newPreset.Tags
+	w.Weight = newPreset.Weight
+	w.MaxConcurrent = newPreset.MaxConcurrent
 
 	w, err = s.repository.Update(w)
 	if err != nil {
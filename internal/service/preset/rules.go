@@ -0,0 +1,142 @@
+package preset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+)
+
+// Select evaluates the rules of the preset in order and returns the preset of the first matching rule.
+// The preset itself is used if no rule matches; rules of the selected preset are not evaluated.
+func (s *Service) Select(preset *model.Preset, subject *dto.PresetRuleSubject) (*model.Preset, error) {
+	if preset.Rules == nil || len(*preset.Rules) == 0 {
+		return preset, nil
+	}
+
+	b, err := json.Marshal(subject)
+	if err != nil {
+		return nil, err
+	}
+	document := string(b)
+
+	for i, rule := range *preset.Rules {
+		if !matchesRule(document, rule) {
+			continue
+		}
+		debug.Log.Debug("preset rule %d matched (uuid: %s): selected preset %s", i, preset.UUID, rule.Preset)
+		metrics.Gauge("preset.ruleMatched").Inc()
+		selected, err := s.Get(rule.Preset)
+		if err != nil {
+			return nil, err
+		}
+		if selected.Command == "" {
+			return nil, fmt.Errorf("preset '%s' selected by rule %d of preset '%s' has no command", selected.Name, i, preset.Name)
+		}
+		return selected, nil
+	}
+
+	if preset.Command == "" {
+		return nil, fmt.Errorf("no rule of preset '%s' matched and it has no command", preset.Name)
+	}
+	return preset, nil
+}
+
+// validateRules ensures all presets referenced by the rules exist and have a command and all match expressions compile
+func (s *Service) validateRules(rules *dto.PresetRules, command string) error {
+	if rules == nil || len(*rules) == 0 {
+		if command == "" {
+			return errors.New("command is required for presets without rules")
+		}
+		return nil
+	}
+	for _, rule := range *rules {
+		preset, err := s.Get(rule.Preset)
+		if err != nil {
+			return fmt.Errorf("rule references invalid preset '%s': %v", rule.Preset, err)
+		}
+		if preset.Command == "" {
+			return fmt.Errorf("rule references preset '%s' without command", rule.Preset)
+		}
+		for _, condition := range rule.Conditions {
+			if condition.Operator != dto.ConditionMatches {
+				continue
+			}
+			if _, err := regexp.Compile(fmt.Sprint(condition.Value)); err != nil {
+				return fmt.Errorf("invalid match expression of condition on '%s': %v", condition.Field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchesRule reports whether all conditions of the rule match; a rule without conditions always matches
+func matchesRule(document string, rule dto.PresetRule) bool {
+	for _, condition := range rule.Conditions {
+		if !matchesCondition(document, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesCondition compares the value at the field path of the document; string comparisons are case-insensitive
+func matchesCondition(document string, condition dto.PresetCondition) bool {
+	val := gjson.Get(document, condition.Field)
+	switch condition.Operator {
+	case dto.ConditionExists:
+		return val.Exists()
+	case dto.ConditionNotExists:
+		return !val.Exists()
+	}
+	if !val.Exists() {
+		return false
+	}
+
+	expected := fmt.Sprint(condition.Value)
+	switch condition.Operator {
+	case dto.ConditionEq:
+		return equals(val, expected)
+	case dto.ConditionNe:
+		return !equals(val, expected)
+	case dto.ConditionGt, dto.ConditionGte, dto.ConditionLt, dto.ConditionLte:
+		actual, err1 := strconv.ParseFloat(val.String(), 64)
+		want, err2 := strconv.ParseFloat(expected, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		switch condition.Operator {
+		case dto.ConditionGt:
+			return actual > want
+		case dto.ConditionGte:
+			return actual >= want
+		case dto.ConditionLt:
+			return actual < want
+		default:
+			return actual <= want
+		}
+	case dto.ConditionContains:
+		return strings.Contains(strings.ToLower(val.String()), strings.ToLower(expected))
+	case dto.ConditionMatches:
+		matched, err := regexp.MatchString(expected, val.String())
+		return err == nil && matched
+	}
+	return false
+}
+
+func equals(val gjson.Result, expected string) bool {
+	if val.Type == gjson.Number {
+		if want, err := strconv.ParseFloat(expected, 64); err == nil {
+			return val.Float() == want
+		}
+	}
+	return strings.EqualFold(val.String(), expected)
+}
@@ -0,0 +1,348 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/welovemedia/ffmate/v2/internal/cron"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/task"
+	"github.com/welovemedia/ffmate/v2/internal/service/webhook"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+type Repository interface {
+	List(page int, perPage int) (*[]model.Schedule, int64, error)
+	Add(schedule *model.Schedule) (*model.Schedule, error)
+	Update(schedule *model.Schedule) (*model.Schedule, error)
+	First(uuid string) (*model.Schedule, error)
+	Delete(schedule *model.Schedule) error
+	Count() (int64, error)
+
+	ListDue(now int64) (*[]model.Schedule, error)
+	Claim(schedule *model.Schedule, lastRun int64, nextRun int64) (bool, error)
+	UpdateError(uuid string, message string) (*model.Schedule, error)
+}
+
+type Service struct {
+	repository       Repository
+	webhookService   *webhook.Service
+	websocketService *websocket.Service
+	taskService      *task.Service
+}
+
+func NewService(repository Repository, webhookService *webhook.Service, websocketService *websocket.Service, taskService *task.Service) *Service {
+	return &Service{
+		repository:       repository,
+		webhookService:   webhookService,
+		websocketService: websocketService,
+		taskService:      taskService,
+	}
+}
+
+func (s *Service) Get(uuid string) (*model.Schedule, error) {
+	w, err := s.repository.First(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if w == nil {
+		return nil, errors.New("schedule for given uuid not found")
+	}
+
+	return w, nil
+}
+
+func (s *Service) List(page int, perPage int) (*[]model.Schedule, int64, error) {
+	return s.repository.List(page, perPage)
+}
+
+func (s *Service) Add(newSchedule *dto.NewSchedule) (*model.Schedule, error) {
+	nextRun, err := nextRun(newSchedule.Cron, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateInput(newSchedule.InputFile); err != nil {
+		return nil, err
+	}
+
+	w, err := s.repository.Add(&model.Schedule{
+		UUID: uuid.NewString(),
+
+		Name:        newSchedule.Name,
+		Description: newSchedule.Description,
+
+		Cron:    newSchedule.Cron,
+		NextRun: nextRun,
+
+		Preset:     newSchedule.Preset,
+		InputFile:  newSchedule.InputFile,
+		OutputFile: newSchedule.OutputFile,
+		Metadata:   newSchedule.Metadata,
+		Priority:   newSchedule.Priority,
+
+		Suspended: newSchedule.Suspended,
+	})
+	if err != nil {
+		debug.Log.Error("failed to create schedule: %v", err)
+		return nil, err
+	}
+	debug.Schedule.Info("created schedule (uuid: %s)", w.UUID)
+
+	metrics.Gauge("schedule.created").Inc()
+	s.webhookService.Fire(dto.ScheduleCreated, w.ToDTO())
+	s.websocketService.Broadcast(websocket.ScheduleCreated, w.ToDTO())
+
+	return w, nil
+}
+
+func (s *Service) Update(uuid string, newSchedule *dto.NewSchedule) (*model.Schedule, error) {
+	w, err := s.repository.First(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if w == nil {
+		return nil, errors.New("schedule for given uuid not found")
+	}
+
+	next, err := nextRun(newSchedule.Cron, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateInput(newSchedule.InputFile); err != nil {
+		return nil, err
+	}
+
+	w.Name = newSchedule.Name
+	w.Description = newSchedule.Description
+	w.Cron = newSchedule.Cron
+	w.NextRun = next
+	w.Preset = newSchedule.Preset
+	w.InputFile = newSchedule.InputFile
+	w.OutputFile = newSchedule.OutputFile
+	w.Metadata = newSchedule.Metadata
+	w.Priority = newSchedule.Priority
+	w.Suspended = newSchedule.Suspended
+
+	w, err = s.repository.Update(w)
+	if err != nil {
+		debug.Log.Error("failed to update schedule (uuid: %s): %v", uuid, err)
+		return nil, err
+	}
+
+	debug.Schedule.Info("updated schedule (uuid: %s)", w.UUID)
+
+	metrics.Gauge("schedule.updated").Inc()
+	s.webhookService.Fire(dto.ScheduleUpdated, w.ToDTO())
+	s.websocketService.Broadcast(websocket.ScheduleUpdated, w.ToDTO())
+
+	return w, nil
+}
+
+func (s *Service) Delete(uuid string) error {
+	w, err := s.repository.First(uuid)
+	if err != nil {
+		return err
+	}
+
+	if w == nil {
+		return errors.New("schedule for given uuid not found")
+	}
+
+	err = s.repository.Delete(w)
+	if err != nil {
+		debug.Log.Error("failed to delete schedule (uuid: %s)", uuid)
+		return err
+	}
+
+	debug.Schedule.Info("deleted schedule (uuid: %s)", uuid)
+
+	metrics.Gauge("schedule.deleted").Inc()
+	s.webhookService.Fire(dto.ScheduleDeleted, w.ToDTO())
+	s.websocketService.Broadcast(websocket.ScheduleDeleted, w.ToDTO())
+
+	return nil
+}
+
+// isGlob reports whether the input file is a glob pattern matching multiple files
+func isGlob(inputFile string) bool {
+	return strings.ContainsAny(inputFile, "*?[")
+}
+
+// validateInput rejects malformed glob patterns and directories; folders are processed using a glob like "/archive/*.mov"
+func validateInput(inputFile string) error {
+	if isGlob(inputFile) {
+		if _, err := filepath.Match(inputFile, ""); err != nil {
+			return fmt.Errorf("invalid input file pattern: %w", err)
+		}
+		return nil
+	}
+	if info, err := os.Stat(inputFile); err == nil && info.IsDir() {
+		return fmt.Errorf("input file '%s' is a directory, use a glob pattern like '%s' instead", inputFile, filepath.Join(inputFile, "*"))
+	}
+	return nil
+}
+
+// inputFiles returns the input files of a run; glob patterns are expanded to all matching files
+func inputFiles(inputFile string) ([]string, error) {
+	if err := validateInput(inputFile); err != nil {
+		return nil, err
+	}
+	if !isGlob(inputFile) {
+		return []string{inputFile}, nil
+	}
+
+	matches, err := filepath.Glob(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// nextRun returns the next run (unix milliseconds) of the cron expression after the given time, evaluated in local time
+func nextRun(expr string, after time.Time) (int64, error) {
+	c, err := cron.Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	next := c.Next(after)
+	if next.IsZero() {
+		return 0, nil
+	}
+	return next.UnixMilli(), nil
+}
+
+/**
+ * schedule processing
+ */
+
+// Process checks for due schedules at the start of every minute
+func (s *Service) Process() {
+	go func() {
+		for {
+			next := time.Now().Truncate(time.Minute).Add(time.Minute)
+			time.Sleep(time.Until(next))
+			s.ProcessDue()
+		}
+	}()
+}
+
+// ProcessDue creates a task for every schedule that is due; in cluster mode each run is claimed by a single client only
+func (s *Service) ProcessDue() {
+	now := time.Now()
+	schedules, err := s.repository.ListDue(now.UnixMilli())
+	if err != nil {
+		debug.Log.Error("failed to list due schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range *schedules {
+		// missed runs (e.g. while ffmate was offline) are collapsed into a single run
+		next, err := nextRun(schedule.Cron, now)
+		if err != nil {
+			debug.Log.Error("failed to calculate next run for schedule (uuid: %s): %v", schedule.UUID, err)
+			continue
+		}
+
+		claimed, err := s.repository.Claim(&schedule, now.UnixMilli(), next)
+		if err != nil {
+			debug.Log.Error("failed to claim schedule (uuid: %s): %v", schedule.UUID, err)
+			continue
+		}
+		if !claimed {
+			debug.Schedule.Debug("schedule skipped as it was claimed by another client (uuid: %s)", schedule.UUID)
+			continue
+		}
+
+		var runError string
+		if err := s.createTasks(&schedule); err != nil {
+			runError = err.Error()
+		}
+
+		metrics.Gauge("schedule.executed").Inc()
+		// the run has been persisted by the claim, only its outcome is left; the schedule may have been edited in the meantime
+		w, err := s.repository.UpdateError(schedule.UUID, runError)
+		if err != nil {
+			debug.Log.Error("failed to update schedule internally (uuid: %s): %v", schedule.UUID, err)
+			continue
+		}
+		if w != nil {
+			s.websocketService.Broadcast(websocket.ScheduleUpdated, w.ToDTO())
+		}
+	}
+}
+
+// createTasks creates a task for the input file of the schedule, or one for every file matching its glob pattern.
+// Every matching file is processed on every run.
+func (s *Service) createTasks(schedule *model.Schedule) error {
+	files, err := inputFiles(schedule.InputFile)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		debug.Schedule.Debug("no files matched the input pattern of schedule (uuid: %s)", schedule.UUID)
+	}
+
+	var errs []error
+	for _, file := range files {
+		if err := s.createTask(schedule, file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) createTask(schedule *model.Schedule, inputFile string) error {
+	// copy the schedules metadata and add the ffmate metadata map
+	metadata := dto.MetadataMap{}
+	if schedule.Metadata != nil {
+		maps.Copy(metadata, *schedule.Metadata)
+	}
+	metadata["ffmate"] = map[string]map[string]string{
+		"schedule": {
+			"uuid": schedule.UUID,
+			"name": schedule.Name,
+		},
+	}
+
+	task := &dto.NewTask{
+		Preset:     schedule.Preset,
+		Name:       schedule.Name,
+		Metadata:   &metadata,
+		InputFile:  inputFile,
+		OutputFile: schedule.OutputFile,
+	}
+	if schedule.Priority != nil {
+		task.Priority.Set(*schedule.Priority)
+	}
+
+	t, err := s.taskService.Add(context.Background(), task, dto.SCHEDULE, "")
+	if err != nil {
+		debug.Log.Error("failed to create task for schedule (uuid: %s): %v", schedule.UUID, err)
+		return err
+	}
+	debug.Schedule.Debug("created new task (uuid: %s) for schedule (uuid: %s)", t.UUID, schedule.UUID)
+	return nil
+}
+
+func (s *Service) Name() string {
+	return service.Schedule
+}
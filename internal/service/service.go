@@ -4,6 +4,8 @@ const (
 	Preset      = "preset"
 	Webhook     = "webhook"
 	Watchfolder = "watchfolder"
+	Schedule    = "schedule"
+	Janitor     = "janitor"
 	Task        = "task"
 	Websocket   = "websocket"
 	Telemetry   = "telemetry"
@@ -0,0 +1,49 @@
+package settings
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+// apply makes the settings (including the overrides of this node) effective at runtime
+func apply(settings *model.Settings) {
+	maxConcurrentTasks := settings.MaxConcurrentTasks
+	defaultPriority := settings.DefaultPriority
+	for _, node := range settings.Nodes {
+		if node.Identifier != cfg.GetString("ffmate.identifier") {
+			continue
+		}
+		if node.MaxConcurrentTasks != nil {
+			maxConcurrentTasks = node.MaxConcurrentTasks
+		}
+		if node.DefaultPriority != nil {
+			defaultPriority = node.DefaultPriority
+		}
+	}
+
+	cfg.Set("ffmate.settings.maxConcurrentTasks", maxConcurrentTasks)
+	cfg.Set("ffmate.settings.defaultPriority", defaultPriority)
+	cfg.Set("ffmate.settings.retention", settings.Retention)
+}
+
+// MaxConcurrentTasks returns the maximum concurrent tasks (slots) of this node, defaulting to the --max-concurrent-tasks flag
+func MaxConcurrentTasks() int {
+	if n := cfg.GetOrDefault[*uint]("ffmate.settings.maxConcurrentTasks", nil); n != nil {
+		return int(*n)
+	}
+	return cfg.GetInt("ffmate.maxConcurrentTasks")
+}
+
+// DefaultPriority returns the priority of new tasks not defining one
+func DefaultPriority() uint {
+	if p := cfg.GetOrDefault[*uint]("ffmate.settings.defaultPriority", nil); p != nil {
+		return *p
+	}
+	return 0
+}
+
+// Retention returns the task retention settings
+func Retention() *dto.Retention {
+	return cfg.GetOrDefault[*dto.Retention]("ffmate.settings.retention", nil)
+}
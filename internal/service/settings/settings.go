@@ -3,8 +3,11 @@ package settings
 import (
 	"github.com/welovemedia/ffmate/v2/internal/database/model"
 	"github.com/welovemedia/ffmate/v2/internal/database/repository"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
 	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
 )
 
 type Repository interface {
@@ -13,21 +16,64 @@ type Repository interface {
 }
 
 type Service struct {
-	repository Repository
+	repository       Repository
+	websocketService *websocket.Service
 }
 
-func NewService(repository *repository.Settings) *Service {
-	return &Service{
-		repository: repository,
+func NewService(repository *repository.Settings, websocketService *websocket.Service) *Service {
+	s := &Service{
+		repository:       repository,
+		websocketService: websocketService,
 	}
+
+	// apply settings stored by this or another cluster member
+	s.reload()
+	websocketService.HandleCluster(websocket.SettingsUpdated, s.handleSettingsUpdated)
+
+	return s
 }
 
 func (s *Service) Load() (*model.Settings, error) {
 	return s.repository.Load()
 }
 
-func (s *Service) Store(_ *dto.Settings) (*model.Settings, error) {
-	return s.repository.Store(&model.Settings{})
+func (s *Service) Store(newSettings *dto.Settings) (*model.Settings, error) {
+	settings, err := s.repository.Store(&model.Settings{
+		MaxConcurrentTasks: newSettings.MaxConcurrentTasks,
+		DefaultPriority:    newSettings.DefaultPriority,
+		Retention:          newSettings.Retention,
+		Nodes:              newSettings.Nodes,
+	})
+	if err != nil {
+		debug.Log.Error("failed to store settings: %v", err)
+		return nil, err
+	}
+
+	apply(settings)
+	debug.Log.Info("updated settings")
+
+	metrics.Gauge("settings.updated").Inc()
+	s.websocketService.Broadcast(websocket.SettingsUpdated, settings.ToDTO())
+
+	return settings, nil
+}
+
+func (s *Service) reload() *model.Settings {
+	settings, err := s.repository.Load()
+	if err != nil {
+		debug.Log.Error("failed to load settings: %v", err)
+		return nil
+	}
+	apply(settings)
+	return settings
+}
+
+// handleSettingsUpdated applies settings stored by another cluster member
+func (s *Service) handleSettingsUpdated(_ any) {
+	if settings := s.reload(); settings != nil {
+		debug.Log.Debug("applied settings updated by cluster")
+		s.websocketService.BroadcastLocal(websocket.SettingsUpdated, settings.ToDTO())
+	}
 }
 
 func (s *Service) Name() string {
@@ -0,0 +1,113 @@
+package task
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+// ListBatches lists all batches including their summary (without tasks)
+func (s *Service) ListBatches(page int, perPage int) (*[]dto.Batch, int64, error) {
+	uuids, total, err := s.repository.ListBatches(page, perPage)
+	if err != nil {
+		return nil, total, err
+	}
+
+	summaries, err := s.repository.SummarizeBatches(uuids)
+	if err != nil {
+		return nil, total, err
+	}
+
+	batches := []dto.Batch{}
+	for _, uuid := range uuids {
+		batches = append(batches, dto.Batch{UUID: uuid, Summary: summaries[uuid]})
+	}
+	return &batches, total, nil
+}
+
+// CancelBatch cancels all unfinished tasks of the batch
+func (s *Service) CancelBatch(uuid string, cleanup bool) (*dto.BulkOperation, error) {
+	return s.bulkBatch(uuid, "batch.canceled", &dto.NewBulkOperation{
+		Action:  dto.BulkCancel,
+		Cleanup: cleanup,
+		Filter: &dto.TaskFilter{
+			Batch:  uuid,
+			Status: []dto.TaskStatus{dto.Queued, dto.Running, dto.PreProcessing, dto.PostProcessing, dto.Paused},
+		},
+	})
+}
+
+// RestartBatch restarts all tasks of the batch
+func (s *Service) RestartBatch(uuid string) (*dto.BulkOperation, error) {
+	return s.bulkBatch(uuid, "batch.restarted", &dto.NewBulkOperation{
+		Action: dto.BulkRestart,
+		Filter: &dto.TaskFilter{Batch: uuid},
+	})
+}
+
+// DeleteBatch deletes all tasks of the batch
+func (s *Service) DeleteBatch(uuid string) (*dto.BulkOperation, error) {
+	return s.bulkBatch(uuid, "batch.deleted", &dto.NewBulkOperation{
+		Action: dto.BulkDelete,
+		Filter: &dto.TaskFilter{Batch: uuid},
+	})
+}
+
+func (s *Service) bulkBatch(uuid string, metric string, operation *dto.NewBulkOperation) (*dto.BulkOperation, error) {
+	summaries, err := s.repository.SummarizeBatches([]string{uuid})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := summaries[uuid]; !ok {
+		return nil, errors.New("batch for given uuid not found")
+	}
+
+	result, err := s.Bulk(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.Gauge(metric).Inc()
+	debug.Log.Info("applied %s to batch (uuid: %s)", operation.Action, uuid)
+
+	return result, nil
+}
+
+// batchThrottle is the minimum interval between two batch summaries broadcasted for progress updates of running tasks
+const batchThrottle = time.Second
+
+var batchBroadcasts = sync.Map{} // batch uuid -> time of the last throttled broadcast
+
+// throttleBatch broadcasts the batch summary at most once per batchThrottle
+func (s *Service) throttleBatch(uuid string) {
+	if uuid == "" {
+		return
+	}
+
+	now := time.Now()
+	if last, ok := batchBroadcasts.Load(uuid); ok && now.Sub(last.(time.Time)) < batchThrottle {
+		return
+	}
+	batchBroadcasts.Store(uuid, now)
+	s.broadcastBatch(uuid)
+}
+
+// broadcastBatch announces the current summary of the batch to all websocket clients
+func (s *Service) broadcastBatch(uuid string) {
+	if uuid == "" {
+		return
+	}
+
+	summaries, err := s.repository.SummarizeBatches([]string{uuid})
+	if err != nil {
+		debug.Log.Error("failed to summarize batch (uuid: %s): %v", uuid, err)
+		return
+	}
+
+	s.websocketService.Broadcast(websocket.BatchUpdated, &dto.Batch{UUID: uuid, Summary: summaries[uuid]})
+}
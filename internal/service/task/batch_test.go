@@ -0,0 +1,35 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+type summaryRepository struct {
+	Repository
+	summaries int
+}
+
+func (r *summaryRepository) SummarizeBatches(uuids []string) (map[string]*dto.BatchSummary, error) {
+	r.summaries++
+	return map[string]*dto.BatchSummary{}, nil
+}
+
+func TestThrottleBatch(t *testing.T) {
+	repository := &summaryRepository{}
+	s := &Service{repository: repository, websocketService: &websocket.Service{}}
+
+	for range 10 {
+		s.throttleBatch("b1")
+	}
+	assert.Equal(t, 1, repository.summaries, "progress updates are throttled")
+
+	s.throttleBatch("b2")
+	assert.Equal(t, 2, repository.summaries, "batches are throttled separately")
+
+	s.throttleBatch("")
+	assert.Equal(t, 2, repository.summaries, "tasks without batch are ignored")
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"errors"
+
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"goyave.dev/goyave/v5/util/typeutil"
+)
+
+// Bulk applies the action to all tasks selected by uuid and/or filter; failures of single tasks do not abort the operation
+func (s *Service) Bulk(operation *dto.NewBulkOperation) (*dto.BulkOperation, error) {
+	if (operation.Filter == nil || !operation.Filter.HasCriteria()) && len(operation.UUIDs) == 0 {
+		return nil, errors.New("either uuids or a filter with at least one criterion must be set")
+	}
+
+	uuids := operation.UUIDs
+	if operation.Filter != nil {
+		var err error
+		uuids, err = s.repository.ListUUIDs(operation.Filter, operation.UUIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &dto.BulkOperation{
+		Action:    operation.Action,
+		Succeeded: []string{},
+		Failed:    []dto.BulkError{},
+	}
+	for _, uuid := range uuids {
+		var err error
+		switch operation.Action {
+		case dto.BulkCancel:
+			_, err = s.Cancel(uuid, operation.Cleanup)
+		case dto.BulkRestart:
+			_, err = s.Restart(uuid)
+		case dto.BulkDelete:
+			err = s.Delete(uuid)
+		case dto.BulkPriority:
+			_, err = s.Edit(uuid, &dto.UpdateTask{Priority: typeutil.NewUndefined(operation.Priority)})
+		default:
+			return nil, errors.New("unknown bulk action")
+		}
+
+		if err != nil {
+			result.Failed = append(result.Failed, dto.BulkError{UUID: uuid, Error: err.Error()})
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, uuid)
+	}
+
+	metrics.Gauge("task.bulk").Inc()
+	debug.Log.Info("applied bulk %s to %d tasks (%d failed)", operation.Action, len(result.Succeeded), len(result.Failed))
+
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/service/ffmpeg"
+)
+
+// runningTask holds the context of a task processed by this node
+type runningTask struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	task   *model.Task // the task as claimed (used for slot accounting)
+}
+
+// canceledError is the cause of a canceled task context
+type canceledError struct {
+	Cleanup bool
+}
+
+func (e *canceledError) Error() string {
+	return "task canceled by user"
+}
+
+// cancelSignal is sent to the cluster to cancel a task running on another node
+type cancelSignal struct {
+	UUID    string `json:"uuid"`
+	Cleanup bool   `json:"cleanup"`
+}
+
+// taskContext returns the context of a task running on this node
+func (s *Service) taskContext(uuid string) context.Context {
+	if t, ok := taskQueue.Load(uuid); ok {
+		return t.(*runningTask).ctx
+	}
+	return context.Background()
+}
+
+// abortTask cancels the context of a task running on this node and reports whether it was found
+func (s *Service) abortTask(uuid string, cause error) bool {
+	t, ok := taskQueue.Load(uuid)
+	if !ok {
+		return false
+	}
+	t.(*runningTask).cancel(cause)
+	debug.Task.Debug("aborted running task (uuid: %s)", uuid)
+	return true
+}
+
+func (s *Service) handleCancelSignal(payload any) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	var signal cancelSignal
+	if err := json.Unmarshal(b, &signal); err != nil {
+		debug.Task.Error("failed to parse cancel signal: %v", err)
+		return
+	}
+	s.abortTask(signal.UUID, &canceledError{Cleanup: signal.Cleanup})
+}
+
+// checkCanceled finishes the task as canceled (or requeues it on shutdown) if its context was canceled in the meantime
+func (s *Service) checkCanceled(task *model.Task) bool {
+	cause := context.Cause(s.taskContext(task.UUID))
+	if cause == nil {
+		return false
+	}
+	if errors.Is(cause, errShutdown) {
+		s.requeueTask(task)
+		return true
+	}
+	s.cancelTask(task, cause)
+	return true
+}
+
+// cleanupOutput removes a partially written output file of a canceled task if requested
+func (s *Service) cleanupOutput(task *model.Task, cause error) {
+	var canceled *canceledError
+	if !errors.As(cause, &canceled) || !canceled.Cleanup {
+		return
+	}
+	if task.OutputFile == nil || task.OutputFile.Resolved == "" {
+		return
+	}
+	if err := os.Remove(task.OutputFile.Resolved); err != nil && !os.IsNotExist(err) {
+		debug.Task.Warn("failed to remove output file of canceled task (uuid: %s): %v", task.UUID, err)
+		return
+	}
+	debug.Task.Debug("removed output file of canceled task (uuid: %s)", task.UUID)
+}
+
+// terminateOnCancel makes a canceled script receive SIGTERM before it is killed after the grace period
+func terminateOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = ffmpeg.TerminateGracePeriod
+}
@@ -0,0 +1,237 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+)
+
+// validateDependencies ensures all parents exist and can still finish successfully
+func (s *Service) validateDependencies(dependsOn []string) ([]model.TaskDependency, error) {
+	var dependencies []model.TaskDependency
+	for _, parentUUID := range dependsOn {
+		parent, err := s.repository.First(parentUUID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("dependency for given uuid not found (uuid: %s)", parentUUID)
+		}
+		if parent.Status == dto.DoneError || parent.Status == dto.DoneCanceled {
+			return nil, fmt.Errorf("dependency did not finish successfully (uuid: %s)", parentUUID)
+		}
+		dependencies = append(dependencies, model.TaskDependency{DependsOn: parentUUID})
+	}
+	return dependencies, nil
+}
+
+// failWithDependencies finishes a persisted task whose dependency failed after it has been validated;
+// the propagation of that failure ran before the task existed and therefore missed it
+func (s *Service) failWithDependencies(task *model.Task) bool {
+	failed := false
+	for _, dependency := range task.Dependencies {
+		parent, err := s.repository.First(dependency.DependsOn)
+		if err != nil || parent == nil {
+			continue
+		}
+		if parent.Status == dto.DoneError || parent.Status == dto.DoneCanceled {
+			s.propagateFailure(parent.UUID, parent.Status)
+			failed = true
+		}
+	}
+	return failed
+}
+
+// propagateFailure finishes all queued or paused dependents of a failed or canceled task with the same status
+func (s *Service) propagateFailure(uuid string, status dto.TaskStatus) {
+	dependents, err := s.repository.ListPendingDependents(uuid)
+	if err != nil {
+		debug.Log.Error("failed to receive dependents of task (uuid: %s): %v", uuid, err)
+		return
+	}
+
+	for _, dependent := range *dependents {
+		dependent.Status = status
+		dependent.Error = fmt.Sprintf("dependency did not finish successfully (uuid: %s)", uuid)
+		dependent.Progress = 100
+		dependent.Remaining = -1
+		dependent.FinishedAt = time.Now().UnixMilli()
+
+		// Update propagates further down the graph
+		if _, err := s.Update(&dependent); err != nil {
+			debug.Task.Error("failed to update dependent task (uuid: %s): %v", dependent.UUID, err)
+			continue
+		}
+		debug.Task.Info("finished dependent task due to failed dependency (uuid: %s, dependency: %s)", dependent.UUID, uuid)
+	}
+}
+
+// injectDependencies exposes the parents of a task as "ffmate.dependencies" metadata
+// so they can be referenced via e.g. ${METADATA_ffmate.dependencies.0.outputFile}
+func (s *Service) injectDependencies(task *model.Task) {
+	if len(task.Dependencies) == 0 {
+		return
+	}
+
+	var dependencies = []map[string]any{}
+	for _, dependency := range task.Dependencies {
+		parent, err := s.repository.First(dependency.DependsOn)
+		if err != nil || parent == nil {
+			debug.Task.Warn("failed to receive dependency of task (uuid: %s, dependency: %s)", task.UUID, dependency.DependsOn)
+			continue
+		}
+		d := map[string]any{
+			"uuid": parent.UUID,
+			"name": parent.Name,
+		}
+		if parent.InputFile != nil {
+			d["inputFile"] = parent.InputFile.Resolved
+		}
+		if parent.OutputFile != nil {
+			d["outputFile"] = parent.OutputFile.Resolved
+		}
+		dependencies = append(dependencies, d)
+	}
+
+	if task.Metadata == nil {
+		task.Metadata = &dto.MetadataMap{}
+	}
+	ffmate, ok := (*task.Metadata)["ffmate"].(map[string]any)
+	if !ok {
+		ffmate = map[string]any{}
+	}
+	ffmate["dependencies"] = dependencies
+	(*task.Metadata)["ffmate"] = ffmate
+}
+
+// AddWorkflow creates all tasks of a workflow in topological order, resolving local ids to task uuids.
+// Every task is built and validated first and all of them are created in a single transaction,
+// so a failing task does not leave the others queued with incomplete dependencies.
+func (s *Service) AddWorkflow(ctx context.Context, newWorkflow *dto.NewWorkflow) (*dto.Batch, error) {
+	ordered, err := sortWorkflow(newWorkflow.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	batchUUID := uuid.NewString()
+	defer presetCache.Delete(batchUUID)
+
+	ids := map[string]string{}
+	for _, workflowTask := range ordered {
+		ids[workflowTask.ID] = uuid.NewString()
+	}
+
+	built := make([]*model.Task, 0, len(ordered))
+	for _, workflowTask := range ordered {
+		// tasks of the workflow do not exist yet, only dependencies on existing tasks are validated
+		dependsOn := workflowTask.DependsOn
+		workflowTask.NewTask.DependsOn = slices.DeleteFunc(slices.Clone(dependsOn), func(dependency string) bool {
+			_, ok := ids[dependency]
+			return ok
+		})
+
+		task, err := s.build(ctx, workflowTask.NewTask, dto.API, batchUUID)
+		if err != nil {
+			return nil, fmt.Errorf("workflow task '%s': %w", workflowTask.ID, err)
+		}
+
+		task.UUID = ids[workflowTask.ID]
+		task.Dependencies = nil
+		for _, dependency := range dependsOn {
+			if id, ok := ids[dependency]; ok {
+				dependency = id
+			}
+			task.Dependencies = append(task.Dependencies, model.TaskDependency{DependsOn: dependency})
+		}
+		workflowTask.NewTask.DependsOn = dependsOn
+		built = append(built, task)
+	}
+
+	tasks, err := s.repository.AddAll(built)
+	if err != nil {
+		debug.Log.Error("failed to create workflow (batch: %s): %v", batchUUID, err)
+		return nil, err
+	}
+	for i := range tasks {
+		s.created(&tasks[i])
+	}
+	failed := false
+	for i := range tasks {
+		failed = s.failWithDependencies(&tasks[i]) || failed
+	}
+	// the failure propagates to the dependents within the workflow as well
+	if failed {
+		for i := range tasks {
+			if task, err := s.repository.First(tasks[i].UUID); err == nil && task != nil {
+				tasks[i] = *task
+			}
+		}
+	}
+
+	metrics.Gauge("workflow.created").Inc()
+
+	return s.finalizeBatch(batchUUID, tasks), nil
+}
+
+// sortWorkflow orders the tasks of a workflow so that each task follows its dependencies (Kahn's algorithm)
+func sortWorkflow(workflowTasks []*dto.NewWorkflowTask) ([]*dto.NewWorkflowTask, error) {
+	byID := map[string]*dto.NewWorkflowTask{}
+	for _, workflowTask := range workflowTasks {
+		if _, exists := byID[workflowTask.ID]; exists {
+			return nil, fmt.Errorf("duplicate workflow task id '%s'", workflowTask.ID)
+		}
+		byID[workflowTask.ID] = workflowTask
+	}
+
+	inDegree := map[string]int{}
+	dependents := map[string][]string{}
+	for _, workflowTask := range workflowTasks {
+		inDegree[workflowTask.ID] += 0
+		for _, dependency := range workflowTask.DependsOn {
+			// dependencies not found in the workflow reference existing tasks
+			if _, ok := byID[dependency]; !ok {
+				continue
+			}
+			if dependency == workflowTask.ID {
+				return nil, fmt.Errorf("workflow task '%s' depends on itself", workflowTask.ID)
+			}
+			inDegree[workflowTask.ID]++
+			dependents[dependency] = append(dependents[dependency], workflowTask.ID)
+		}
+	}
+
+	// seed in submission order to keep the result deterministic
+	var queue []string
+	for _, workflowTask := range workflowTasks {
+		if inDegree[workflowTask.ID] == 0 {
+			queue = append(queue, workflowTask.ID)
+		}
+	}
+
+	ordered := make([]*dto.NewWorkflowTask, 0, len(workflowTasks))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		ordered = append(ordered, byID[id])
+		for _, dependent := range dependents[id] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	if len(ordered) != len(workflowTasks) {
+		return nil, errors.New("workflow contains a dependency cycle")
+	}
+
+	return ordered, nil
+}
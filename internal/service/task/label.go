@@ -0,0 +1,30 @@
+package task
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+)
+
+// taskLabels converts the required labels of a new task, skipping empty and duplicate labels
+func taskLabels(labels []string) []model.TaskLabel {
+	var taskLabels []model.TaskLabel
+	for _, label := range uniqueTags(labels) {
+		taskLabels = append(taskLabels, model.TaskLabel{Label: label})
+	}
+	return taskLabels
+}
+
+// uniqueTags trims the given tags, skipping empty and duplicate ones
+func uniqueTags(tags []string) []string {
+	var unique []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(unique, tag) {
+			continue
+		}
+		unique = append(unique, tag)
+	}
+	return unique
+}
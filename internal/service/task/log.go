@@ -0,0 +1,128 @@
+package task
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+var taskLoggers = sync.Map{}
+
+// taskLogger buffers the output of a running task and periodically persists and broadcasts it
+type taskLogger struct {
+	service *Service
+	done    chan struct{}
+	task    string
+	buffer  []*model.TaskLog
+	mu      sync.Mutex
+}
+
+func (s *Service) startTaskLogger(uuid string) *taskLogger {
+	l := &taskLogger{
+		service: s,
+		task:    uuid,
+		done:    make(chan struct{}),
+	}
+	taskLoggers.Store(uuid, l)
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.flush()
+			case <-l.done:
+				return
+			}
+		}
+	}()
+
+	return l
+}
+
+// taskLogger returns the logger of a running task (or nil if the task is not running on this node)
+func (s *Service) taskLogger(uuid string) *taskLogger {
+	if l, ok := taskLoggers.Load(uuid); ok {
+		return l.(*taskLogger)
+	}
+	return nil
+}
+
+func (l *taskLogger) Log(source dto.TaskLogSource, line string) {
+	if l == nil {
+		return
+	}
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return
+	}
+	l.mu.Lock()
+	l.buffer = append(l.buffer, &model.TaskLog{Task: l.task, Source: source, Line: line, CreatedAt: time.Now().UnixMilli()})
+	l.mu.Unlock()
+}
+
+func (l *taskLogger) flush() {
+	l.mu.Lock()
+	buffer := l.buffer
+	l.buffer = nil
+	l.mu.Unlock()
+
+	if len(buffer) == 0 {
+		return
+	}
+
+	if err := l.service.logRepository.Add(buffer); err != nil {
+		debug.Log.Error("failed to save task logs (uuid: %s): %v", l.task, err)
+		return
+	}
+
+	var taskLogDTOs = []*dto.TaskLog{}
+	for _, taskLog := range buffer {
+		taskLogDTOs = append(taskLogDTOs, taskLog.ToDTO())
+	}
+	l.service.websocketService.Broadcast(websocket.TaskLogCreated, taskLogDTOs)
+}
+
+// Close stops the periodic flushing and persists all remaining lines
+func (l *taskLogger) Close() {
+	taskLoggers.Delete(l.task)
+	close(l.done)
+	l.flush()
+}
+
+// Writer returns a writer logging each written line for the given source
+func (l *taskLogger) Writer(source dto.TaskLogSource) *taskLogWriter {
+	return &taskLogWriter{logger: l, source: source}
+}
+
+type taskLogWriter struct {
+	logger  *taskLogger
+	source  dto.TaskLogSource
+	partial strings.Builder
+}
+
+func (w *taskLogWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b == '\n' {
+			w.logger.Log(w.source, w.partial.String())
+			w.partial.Reset()
+			continue
+		}
+		w.partial.WriteByte(b)
+	}
+	return len(p), nil
+}
+
+// Flush logs a trailing line that was not terminated by a newline
+func (w *taskLogWriter) Flush() {
+	if w.partial.Len() > 0 {
+		w.logger.Log(w.source, w.partial.String())
+		w.partial.Reset()
+	}
+}
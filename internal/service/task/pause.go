@@ -0,0 +1,152 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+// taskSignal is sent to the cluster to control a task running on another node
+type taskSignal struct {
+	UUID string `json:"uuid"`
+}
+
+// Pause suspends a task. Tasks running on another node keep their status until the owning node
+// confirms the suspended process; if that node is gone the task is reclaimed once the node is marked offline.
+func (s *Service) Pause(uuid string) (*model.Task, error) {
+	w, err := s.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	switch w.Status {
+	case dto.Queued:
+		return s.pauseQueued(w)
+	case dto.Running:
+		if _, ok := taskQueue.Load(uuid); !ok {
+			return w, s.signalCluster(uuid, websocket.TaskPause)
+		}
+		if err := s.ffmpegService.Pause(uuid); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("task can only be paused while queued or running")
+	}
+
+	return s.markPaused(w)
+}
+
+// Resume continues a paused task; like Pause, the status of tasks running on another node is updated by the owning node
+func (s *Service) Resume(uuid string) (*model.Task, error) {
+	w, err := s.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Status != dto.Paused {
+		return nil, errors.New("task is not paused")
+	}
+
+	// tasks paused before being processed return to the queue
+	if w.StartedAt > 0 {
+		if _, ok := taskQueue.Load(uuid); !ok {
+			return w, s.signalCluster(uuid, websocket.TaskResume)
+		}
+		if err := s.ffmpegService.Resume(uuid); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.markResumed(w)
+}
+
+// pauseQueued pauses a queued task unless it has been claimed by a node in the meantime
+func (s *Service) pauseQueued(w *model.Task) (*model.Task, error) {
+	w.Status = dto.Paused
+	task, err := s.repository.UpdateIfStatus(w, dto.Queued)
+	if err != nil {
+		debug.Log.Error("failed to pause task (uuid: %s): %v", w.UUID, err)
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task status changed during the update, please try again")
+	}
+
+	metrics.Gauge("task.paused").Inc()
+	debug.Log.Info("paused task (uuid: %s)", task.UUID)
+
+	s.updated(task)
+	return task, nil
+}
+
+func (s *Service) markPaused(w *model.Task) (*model.Task, error) {
+	w.Status = dto.Paused
+
+	metrics.Gauge("task.paused").Inc()
+	debug.Log.Info("paused task (uuid: %s)", w.UUID)
+
+	return s.Update(w)
+}
+
+func (s *Service) markResumed(w *model.Task) (*model.Task, error) {
+	if w.StartedAt == 0 {
+		w.Status = dto.Queued
+	} else {
+		w.Status = dto.Running
+	}
+
+	metrics.Gauge("task.resumed").Inc()
+	debug.Log.Info("resumed task (uuid: %s)", w.UUID)
+
+	return s.Update(w)
+}
+
+// signalCluster sends the signal to the node processing the task
+func (s *Service) signalCluster(uuid string, subject websocket.WebsocketSubject) error {
+	if !cfg.GetBool("ffmate.isCluster") {
+		return errors.New("task is not running on this node")
+	}
+	s.websocketService.NotifyCluster(subject, &taskSignal{UUID: uuid})
+	return nil
+}
+
+// handleSignal returns a cluster handler applying the signal to tasks running on this node
+// and confirming it by updating the status of the task
+func (s *Service) handleSignal(signal func(uuid string) error, status dto.TaskStatus, confirm func(w *model.Task) (*model.Task, error)) func(payload any) {
+	return func(payload any) {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		var ts taskSignal
+		if err := json.Unmarshal(b, &ts); err != nil {
+			debug.Task.Error("failed to parse task signal: %v", err)
+			return
+		}
+		if _, ok := taskQueue.Load(ts.UUID); !ok {
+			return
+		}
+		if err := signal(ts.UUID); err != nil {
+			debug.Task.Error("failed to signal task (uuid: %s): %v", ts.UUID, err)
+			return
+		}
+
+		w, err := s.Get(ts.UUID)
+		if err != nil {
+			debug.Task.Error("failed to receive signaled task (uuid: %s): %v", ts.UUID, err)
+			return
+		}
+		if w.Status != status {
+			return
+		}
+		if _, err := confirm(w); err != nil {
+			debug.Task.Error("failed to update signaled task (uuid: %s): %v", ts.UUID, err)
+		}
+	}
+}
@@ -2,10 +2,10 @@ package task
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -28,9 +28,10 @@ func (s *Service) runPreProcessing(task *model.Task) error {
 }
 
 func (s *Service) prepareTaskFiles(task *model.Task) {
-	task.InputFile.Resolved = s.wildcardReplacer(task.InputFile.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata)
-	task.OutputFile.Resolved = s.wildcardReplacer(task.OutputFile.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata)
-	task.Command.Resolved = s.wildcardReplacer(task.Command.Raw, task.InputFile.Resolved, task.OutputFile.Resolved, task.Source, task.Metadata)
+	task.InputFile.Resolved = s.wildcardReplacer(task.InputFile.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata, task.Probe)
+	s.probeInput(task)
+	task.OutputFile.Resolved = s.wildcardReplacer(task.OutputFile.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata, task.Probe)
+	task.Command.Resolved = s.wildcardReplacer(task.Command.Raw, task.InputFile.Resolved, task.OutputFile.Resolved, task.Source, task.Metadata, task.Probe)
 
 	task.Status = dto.Running
 	if _, err := s.Update(task); err != nil {
@@ -38,6 +39,20 @@ func (s *Service) prepareTaskFiles(task *model.Task) {
 	}
 }
 
+// probeInput stores the media information of the resolved input file on the task; a failing probe does not fail the task
+func (s *Service) probeInput(task *model.Task) {
+	if task.InputFile.Resolved == "" {
+		return
+	}
+	probe, err := s.ffmpegService.Probe(s.taskContext(task.UUID), task.InputFile.Resolved)
+	if err != nil {
+		debug.Task.Debug("failed to probe input file (uuid: %s): %v", task.UUID, err)
+		s.taskLogger(task.UUID).Log(dto.TaskLogProbe, err.Error())
+		return
+	}
+	task.Probe = probe
+}
+
 func (s *Service) createOutputDirectory(task *model.Task) error {
 	if err := os.MkdirAll(filepath.Dir(task.OutputFile.Resolved), 0755); err != nil {
 		return fmt.Errorf("failed to create non-existing output directory: %v", err)
@@ -48,14 +63,20 @@ func (s *Service) createOutputDirectory(task *model.Task) error {
 func (s *Service) executeFFmpeg(task *model.Task) error {
 	debug.Task.Debug("starting ffmpeg process (uuid: %s)", task.UUID)
 
-	ctxAny, _ := taskQueue.Load(task.UUID)
-	ctx := ctxAny.(context.Context)
+	ctx := s.taskContext(task.UUID)
 
 	err := s.ffmpegService.Execute(&ffmpeg.ExecutionRequest{
 		Task:    task,
 		Command: task.Command.Resolved,
 		Ctx:     ctx,
+		LogFunc: func(line string) {
+			s.taskLogger(task.UUID).Log(dto.TaskLogFFmpeg, line)
+		},
 		UpdateFunc: func(progress, remaining float64) {
+			// do not overwrite the status of a canceled task
+			if ctx.Err() != nil {
+				return
+			}
 			task.Progress = progress
 			task.Remaining = remaining
 			if _, err := s.Update(task); err != nil {
@@ -70,9 +91,8 @@ func (s *Service) executeFFmpeg(task *model.Task) error {
 	if err != nil {
 		debug.Task.Debug("finished processing with error (uuid: %s): %v", task.UUID, err)
 
-		if cause := context.Cause(ctx); cause != nil {
-			s.cancelTask(task, cause)
-			return cause
+		if s.checkCanceled(task) {
+			return err
 		}
 
 		s.failTask(task, err)
@@ -155,9 +175,9 @@ func (s *Service) handleSidecar(task *model.Task, processor *dto.PrePostProcessi
 
 	// Resolve path and save
 	if processorType == "pre" {
-		processor.SidecarPath.Resolved = s.wildcardReplacer(processor.SidecarPath.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata)
+		processor.SidecarPath.Resolved = s.wildcardReplacer(processor.SidecarPath.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata, task.Probe)
 	} else {
-		processor.SidecarPath.Resolved = s.wildcardReplacer(processor.SidecarPath.Raw, task.InputFile.Resolved, task.OutputFile.Resolved, task.Source, task.Metadata)
+		processor.SidecarPath.Resolved = s.wildcardReplacer(processor.SidecarPath.Raw, task.InputFile.Resolved, task.OutputFile.Resolved, task.Source, task.Metadata, task.Probe)
 	}
 	if _, err := s.Update(task); err != nil {
 		debug.Log.Error("failed to save task (uuid: %s)", task.UUID)
@@ -186,9 +206,9 @@ func (s *Service) handleScriptExecution(task *model.Task, processor *dto.PrePost
 	}
 
 	if processorType == "pre" {
-		processor.ScriptPath.Resolved = s.wildcardReplacer(processor.ScriptPath.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata)
+		processor.ScriptPath.Resolved = s.wildcardReplacer(processor.ScriptPath.Raw, task.InputFile.Raw, task.OutputFile.Raw, task.Source, task.Metadata, task.Probe)
 	} else {
-		processor.ScriptPath.Resolved = s.wildcardReplacer(processor.ScriptPath.Raw, task.InputFile.Resolved, task.OutputFile.Resolved, task.Source, task.Metadata)
+		processor.ScriptPath.Resolved = s.wildcardReplacer(processor.ScriptPath.Raw, task.InputFile.Resolved, task.OutputFile.Resolved, task.Source, task.Metadata, task.Probe)
 	}
 	if _, err := s.Update(task); err != nil {
 		debug.Log.Error("failed to save task (uuid: %s)", task.UUID)
@@ -201,9 +221,20 @@ func (s *Service) handleScriptExecution(task *model.Task, processor *dto.PrePost
 		return nil
 	}
 
-	cmd := exec.Command(args[0], args[1:]...)
+	source := dto.TaskLogPreProcessing
+	if processorType == "post" {
+		source = dto.TaskLogPostProcessing
+	}
+	stdoutLog := s.taskLogger(task.UUID).Writer(source)
+	stderrLog := s.taskLogger(task.UUID).Writer(source)
+	defer stdoutLog.Flush()
+	defer stderrLog.Flush()
+
+	cmd := exec.CommandContext(s.taskContext(task.UUID), args[0], args[1:]...)
+	terminateOnCancel(cmd)
 	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	cmd.Stdout = stdoutLog
+	cmd.Stderr = io.MultiWriter(&stderr, stderrLog)
 
 	if err := cmd.Start(); err != nil || cmd.Wait() != nil {
 		processor.Error = fmt.Sprintf("%s (exit code: %d)", stderr.String(), cmd.ProcessState.ExitCode())
@@ -220,9 +251,12 @@ func (s *Service) reimportSidecarIfNeeded(task *model.Task, processor *dto.PrePo
 	if err != nil {
 		return err
 	}
+	// the sidecar does not contain the webhook secrets
+	webhooks := task.Webhooks
 	if err := json.Unmarshal(data, task); err != nil {
 		return err
 	}
+	task.Webhooks.KeepSecrets(webhooks)
 	debug.Task.Debug("re-imported sidecar file (uuid: %s)", task.UUID)
 	return nil
 }
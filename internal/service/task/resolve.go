@@ -0,0 +1,69 @@
+package task
+
+import (
+	"context"
+	"slices"
+
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+// Resolve merges the preset and replaces all wildcards the same way the processing does, without queuing the task
+func (s *Service) Resolve(ctx context.Context, newTask *dto.NewTask) (*dto.ResolvedTask, error) {
+	task, err := s.build(ctx, newTask, dto.API, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// expose the dependencies like the processing does, so their wildcards are known
+	s.injectDependencies(task)
+
+	resolve := func(input string, inputFile string, outputFile string) string {
+		return s.wildcardReplacer(input, inputFile, outputFile, task.Source, task.Metadata, task.Probe)
+	}
+
+	resolved := &dto.ResolvedTask{}
+	resolved.InputFile = resolve(task.InputFile.Raw, task.InputFile.Raw, task.OutputFile.Raw)
+	resolved.Preset = task.Preset
+	if resolved.InputFile != "" && task.Probe == nil {
+		if task.Probe, err = s.ffmpegService.Probe(ctx, resolved.InputFile); err != nil {
+			resolved.ProbeError = err.Error()
+		}
+	}
+	resolved.Probe = task.Probe
+	resolved.OutputFile = resolve(task.OutputFile.Raw, task.InputFile.Raw, task.OutputFile.Raw)
+	resolved.Command = resolve(task.Command.Raw, resolved.InputFile, resolved.OutputFile)
+
+	raws := []string{task.InputFile.Raw, task.OutputFile.Raw, task.Command.Raw}
+
+	// pre-processing is resolved with the raw, post-processing with the resolved files
+	if p := task.PreProcessing; p != nil {
+		resolved.PreProcessing = &dto.ResolvedProcessing{
+			ScriptPath:  resolve(p.ScriptPath.Raw, task.InputFile.Raw, task.OutputFile.Raw),
+			SidecarPath: resolve(p.SidecarPath.Raw, task.InputFile.Raw, task.OutputFile.Raw),
+		}
+		raws = append(raws, p.ScriptPath.Raw, p.SidecarPath.Raw)
+	}
+	if p := task.PostProcessing; p != nil {
+		resolved.PostProcessing = &dto.ResolvedProcessing{
+			ScriptPath:  resolve(p.ScriptPath.Raw, resolved.InputFile, resolved.OutputFile),
+			SidecarPath: resolve(p.SidecarPath.Raw, resolved.InputFile, resolved.OutputFile),
+		}
+		raws = append(raws, p.ScriptPath.Raw, p.SidecarPath.Raw)
+	}
+
+	resolved.UnknownWildcards = []string{}
+	for _, raw := range raws {
+		for _, wildcard := range unknownWildcards(raw, task.Metadata, task.Probe) {
+			if !slices.Contains(resolved.UnknownWildcards, wildcard) {
+				resolved.UnknownWildcards = append(resolved.UnknownWildcards, wildcard)
+			}
+		}
+	}
+
+	resolved.Argv, err = s.ffmpegService.ParseCommand(resolved.Command)
+	if err != nil {
+		resolved.Error = err.Error()
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,95 @@
+package task
+
+import (
+	"math"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+)
+
+// recordAttempt appends the finished attempt to the tasks attempt history
+func (s *Service) recordAttempt(task *model.Task, err error) {
+	if task.Attempts == nil {
+		task.Attempts = &dto.TaskAttempts{}
+	}
+	attempt := dto.TaskAttempt{
+		Attempt:    task.Attempt,
+		StartedAt:  task.StartedAt,
+		FinishedAt: task.FinishedAt,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	*task.Attempts = append(*task.Attempts, attempt)
+}
+
+// shouldRetry reports whether the retry policy of a failed task allows another attempt
+func (s *Service) shouldRetry(task *model.Task, err error) bool {
+	if task.Retry == nil || task.Attempt >= task.Retry.MaxAttempts {
+		return false
+	}
+
+	re, e := task.Retry.Matcher()
+	if e != nil {
+		debug.Task.Warn("invalid retry match expression (uuid: %s): %v", task.UUID, e)
+		return false
+	}
+	if re != nil && !re.MatchString(err.Error()) {
+		debug.Task.Debug("error does not match retry expression (uuid: %s)", task.UUID)
+		return false
+	}
+
+	return true
+}
+
+// RetryMaxDelay caps the delay between two attempts of a task
+var RetryMaxDelay = 24 * time.Hour
+
+// retryDelay calculates the delay before the next attempt based on the policies backoff factor, up to RetryMaxDelay
+func (s *Service) retryDelay(policy *dto.RetryPolicy, attempt uint) time.Duration {
+	delay := float64(policy.Delay) * float64(time.Second)
+	if policy.Backoff > 1 && attempt > 1 {
+		delay *= math.Pow(policy.Backoff, float64(attempt-1))
+	}
+	// compare before converting, a float exceeding the range of a duration does not convert
+	if delay > float64(RetryMaxDelay) {
+		return RetryMaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// resetTask resets the progress of a task so it can be processed again
+func (s *Service) resetTask(task *model.Task) {
+	task.Progress = 0
+	task.Remaining = 0
+	task.StartedAt = 0
+	task.FinishedAt = 0
+
+	// reset pre/post-processing so their scripts are executed again
+	for _, processor := range []*dto.PrePostProcessing{task.PreProcessing, task.PostProcessing} {
+		if processor != nil {
+			processor.Error = ""
+			processor.StartedAt = 0
+			processor.FinishedAt = 0
+		}
+	}
+}
+
+func (s *Service) retryTask(task *model.Task, err error) {
+	delay := s.retryDelay(task.Retry, task.Attempt)
+
+	s.resetTask(task)
+	task.Status = dto.Queued
+	task.Error = err.Error()
+	task.RetryAt = time.Now().Add(delay).UnixMilli()
+
+	if _, err := s.Update(task); err != nil {
+		debug.Task.Error("failed to update task after retry (uuid: %s)", task.UUID)
+	}
+
+	metrics.Gauge("task.retried").Inc()
+	debug.Task.Info("task failed, retrying in %s (uuid: %s, attempt: %d/%d)", delay, task.UUID, task.Attempt, task.Retry.MaxAttempts)
+}
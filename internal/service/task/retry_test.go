@@ -0,0 +1,64 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+func TestRetryDelay(t *testing.T) {
+	s := &Service{}
+
+	constant := &dto.RetryPolicy{Delay: 10}
+	assert.Equal(t, 10*time.Second, s.retryDelay(constant, 1))
+	assert.Equal(t, 10*time.Second, s.retryDelay(constant, 3))
+
+	backoff := &dto.RetryPolicy{Delay: 10, Backoff: 2}
+	assert.Equal(t, 10*time.Second, s.retryDelay(backoff, 1))
+	assert.Equal(t, 20*time.Second, s.retryDelay(backoff, 2))
+	assert.Equal(t, 40*time.Second, s.retryDelay(backoff, 3))
+
+	fractional := &dto.RetryPolicy{Delay: 4, Backoff: 1.5}
+	assert.Equal(t, 9*time.Second, s.retryDelay(fractional, 3))
+
+	assert.Equal(t, time.Duration(0), s.retryDelay(&dto.RetryPolicy{}, 2))
+
+	// large delays and backoffs are capped instead of overflowing
+	assert.Equal(t, RetryMaxDelay, s.retryDelay(&dto.RetryPolicy{Delay: 86400, Backoff: 2}, 2))
+	assert.Equal(t, RetryMaxDelay, s.retryDelay(&dto.RetryPolicy{Delay: 10, Backoff: 10}, 100))
+	assert.Equal(t, RetryMaxDelay, s.retryDelay(&dto.RetryPolicy{Delay: 1 << 40}, 1))
+}
+
+func TestShouldRetry(t *testing.T) {
+	s := &Service{}
+	stderr := errors.New("[tcp @ 0x1] Connection to tcp://origin:80 failed: Connection timed out")
+
+	assert.False(t, s.shouldRetry(&model.Task{Attempt: 1}, stderr), "no policy")
+	assert.True(t, s.shouldRetry(&model.Task{Attempt: 1, Retry: &dto.RetryPolicy{MaxAttempts: 3}}, stderr), "no expression")
+	assert.False(t, s.shouldRetry(&model.Task{Attempt: 3, Retry: &dto.RetryPolicy{MaxAttempts: 3}}, stderr), "attempts exhausted")
+
+	policy := &dto.RetryPolicy{MaxAttempts: 3, Match: `(?i)connection (timed out|refused)`}
+	assert.True(t, s.shouldRetry(&model.Task{Attempt: 1, Retry: policy}, stderr), "matching stderr")
+	assert.False(t, s.shouldRetry(&model.Task{Attempt: 1, Retry: policy}, errors.New("Invalid data found when processing input")), "non-matching stderr")
+
+	assert.False(t, s.shouldRetry(&model.Task{Attempt: 1, Retry: &dto.RetryPolicy{MaxAttempts: 3, Match: "("}}, stderr), "invalid expression")
+}
+
+func TestRetryMatcherIsCompiledOnce(t *testing.T) {
+	policy := &dto.RetryPolicy{Match: "timed out"}
+	first, err := policy.Matcher()
+	assert.NoError(t, err)
+	second, _ := (&dto.RetryPolicy{Match: "timed out"}).Matcher()
+	assert.Same(t, first, second)
+
+	_, err = (&dto.RetryPolicy{Match: "("}).Matcher()
+	assert.Error(t, err)
+
+	re, err := (&dto.RetryPolicy{}).Matcher()
+	assert.NoError(t, err)
+	assert.Nil(t, re)
+}
@@ -0,0 +1,94 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/ffmpeg"
+)
+
+// errShutdown is the cause of tasks aborted due to the shutdown of this node
+var errShutdown = errors.New("task aborted due to shutdown")
+
+var shuttingDown atomic.Bool
+
+// Shutdown stops picking up new tasks and waits up to the given timeout for running tasks to finish.
+// Tasks still running afterward are aborted and returned to the queue.
+func (s *Service) Shutdown(timeout time.Duration) {
+	shuttingDown.Store(true)
+
+	if running := s.RunningTasks(); running > 0 {
+		debug.Log.Info("waiting up to %s for %d running tasks to finish", timeout, running)
+		if !s.waitForRunningTasks(timeout) {
+			taskQueue.Range(func(uuid, _ any) bool {
+				s.abortTask(uuid.(string), errShutdown)
+				return true
+			})
+			// give the processes time to terminate and the tasks to be requeued
+			s.waitForRunningTasks(3 * ffmpeg.TerminateGracePeriod)
+		}
+	}
+
+	if running := s.RunningTasks(); running > 0 {
+		debug.Log.Warn("shutting down with %d tasks still running", running)
+	}
+}
+
+func (s *Service) waitForRunningTasks(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for s.RunningTasks() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// requeueTask returns an aborted task to the queue without counting it as an attempt
+func (s *Service) requeueTask(task *model.Task) {
+	s.resetTask(task)
+	task.Status = dto.Queued
+	if task.Attempt > 0 {
+		task.Attempt--
+	}
+
+	if _, err := s.Update(task); err != nil {
+		debug.Task.Error("failed to update task after requeue (uuid: %s)", task.UUID)
+	}
+
+	metrics.Gauge("task.requeued").Inc()
+	debug.Task.Info("task requeued (uuid: %s)", task.UUID)
+}
+
+// ReclaimTasks handles tasks left running by a previous run of this client or a client that went offline
+func (s *Service) ReclaimTasks(identifier string) {
+	tasks, err := s.repository.ListRunningByClient(identifier)
+	if err != nil {
+		debug.Log.Error("failed to receive orphaned tasks: %v", err)
+		return
+	}
+
+	for _, task := range *tasks {
+		if cfg.GetOrDefault("ffmate.recoverOrphaned", "requeue") == "fail" {
+			task.FinishedAt = time.Now().UnixMilli()
+			task.Progress = 100
+			task.Remaining = -1
+			task.Status = dto.DoneError
+			task.Error = fmt.Sprintf("task was orphaned by its client (identifier: %s)", identifier)
+			if _, err := s.Update(&task); err != nil {
+				debug.Task.Error("failed to update orphaned task (uuid: %s)", task.UUID)
+			}
+			debug.Task.Warn("failed orphaned task (uuid: %s)", task.UUID)
+			continue
+		}
+		s.requeueTask(&task)
+	}
+}
@@ -0,0 +1,83 @@
+package task
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+)
+
+// slots tracks the free capacity of this node and the concurrency limits while claiming queued tasks
+type slots struct {
+	presetLimits map[string]uint
+	tagLimits    map[string]int
+	presets      map[string]int
+	tags         map[string]int
+	capacity     int
+	free         int
+	blocked      bool
+}
+
+// taskWeight returns the slots a task occupies (a task heavier than the capacity occupies the whole node)
+func taskWeight(task *model.Task, capacity int) int {
+	return min(max(int(task.Weight), 1), capacity)
+}
+
+// newSlots calculates the free capacity based on the (unpaused) tasks processed by this node
+func (s *Service) newSlots(capacity int, presetLimits map[string]uint) *slots {
+	sl := &slots{
+		presetLimits: presetLimits,
+		tagLimits:    cfg.GetOrDefault("ffmate.concurrencyLimits", map[string]int{}),
+		presets:      map[string]int{},
+		tags:         map[string]int{},
+		capacity:     capacity,
+		free:         capacity,
+	}
+
+	taskQueue.Range(func(uuid, t any) bool {
+		// paused tasks free up their slots
+		if !s.ffmpegService.IsPaused(uuid.(string)) {
+			sl.take(t.(*runningTask).task)
+		}
+		return true
+	})
+
+	return sl
+}
+
+func (sl *slots) take(task *model.Task) {
+	sl.free -= taskWeight(task, sl.capacity)
+	sl.presets[task.Preset]++
+	for _, tag := range task.Tags {
+		sl.tags[tag]++
+	}
+}
+
+// accept reports whether the task fits into the free capacity without exceeding a concurrency limit and takes its slots
+func (sl *slots) accept(task *model.Task) bool {
+	if sl.blocked {
+		return false
+	}
+
+	if limit, ok := sl.presetLimits[task.Preset]; ok && task.Preset != "" && sl.presets[task.Preset] >= int(limit) {
+		return false
+	}
+	for _, tag := range task.Tags {
+		if limit, ok := sl.tagLimits[tag]; ok && sl.tags[tag] >= limit {
+			return false
+		}
+	}
+
+	if taskWeight(task, sl.capacity) > sl.free {
+		// lighter tasks must not overtake a heavier one waiting for free slots
+		sl.blocked = true
+		return false
+	}
+
+	sl.take(task)
+	return true
+}
+
+// claim accepts the task like accept and additionally reports whether further tasks may still be accepted
+func (sl *slots) claim(task *model.Task) (bool, bool) {
+	accepted := sl.accept(task)
+	return accepted, !sl.blocked && sl.free > 0
+}
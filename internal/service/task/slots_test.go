@@ -0,0 +1,62 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+)
+
+func newTestSlots(capacity int) *slots {
+	return &slots{
+		presetLimits: map[string]uint{"hevc4k": 1},
+		tagLimits:    map[string]int{"audio": 2},
+		presets:      map[string]int{},
+		tags:         map[string]int{},
+		capacity:     capacity,
+		free:         capacity,
+	}
+}
+
+func TestSlotsPresetAndTagLimits(t *testing.T) {
+	sl := newTestSlots(10)
+
+	assert.True(t, sl.accept(&model.Task{Preset: "hevc4k", Weight: 4}))
+	assert.False(t, sl.accept(&model.Task{Preset: "hevc4k", Weight: 4}), "preset limit reached")
+	assert.True(t, sl.accept(&model.Task{Tags: []string{"audio"}}))
+	assert.True(t, sl.accept(&model.Task{Tags: []string{"audio"}}))
+	assert.False(t, sl.accept(&model.Task{Tags: []string{"audio"}}), "tag limit reached")
+	assert.Equal(t, 4, sl.free)
+}
+
+func TestSlotsWeight(t *testing.T) {
+	sl := newTestSlots(4)
+
+	assert.True(t, sl.accept(&model.Task{Weight: 3}))
+	assert.False(t, sl.accept(&model.Task{Weight: 2}), "not enough free slots")
+	assert.False(t, sl.accept(&model.Task{Weight: 1}), "lighter tasks must not overtake")
+
+	// tasks heavier than the capacity occupy the whole node
+	sl = newTestSlots(4)
+	assert.True(t, sl.accept(&model.Task{Weight: 8}))
+	assert.Equal(t, 0, sl.free)
+}
+
+func TestSlotsClaim(t *testing.T) {
+	sl := newTestSlots(2)
+
+	accepted, more := sl.claim(&model.Task{Preset: "hevc4k", Weight: 1})
+	assert.True(t, accepted)
+	assert.True(t, more)
+	accepted, more = sl.claim(&model.Task{Preset: "hevc4k"})
+	assert.False(t, accepted, "preset limit reached")
+	assert.True(t, more, "lighter tasks may follow")
+	accepted, more = sl.claim(&model.Task{Weight: 2})
+	assert.False(t, accepted, "not enough free slots")
+	assert.False(t, more, "lighter tasks must not overtake")
+
+	sl = newTestSlots(2)
+	accepted, more = sl.claim(&model.Task{Weight: 2})
+	assert.True(t, accepted)
+	assert.False(t, more, "no free slots left")
+}
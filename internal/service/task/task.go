@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,41 +17,67 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/metrics"
 	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/client"
 	"github.com/welovemedia/ffmate/v2/internal/service/ffmpeg"
 	"github.com/welovemedia/ffmate/v2/internal/service/preset"
+	"github.com/welovemedia/ffmate/v2/internal/service/settings"
 	"github.com/welovemedia/ffmate/v2/internal/service/webhook"
 	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
 )
 
 type Repository interface {
-	List(page int, perPage int) (*[]model.Task, int64, error)
+	List(page int, perPage int, filter *dto.TaskFilter) (*[]model.Task, int64, error)
 	ListByBatch(uuid string, page int, perPage int) (*[]model.Task, int64, error)
 	Add(task *model.Task) (*model.Task, error)
+	AddAll(tasks []*model.Task) ([]model.Task, error)
 	Update(task *model.Task) (*model.Task, error)
+	UpdateProcessed(task *model.Task) (*model.Task, error)
+	UpdatePriority(uuid string, status dto.TaskStatus, priority uint) (*model.Task, error)
+	UpdateIfStatus(task *model.Task, status dto.TaskStatus) (*model.Task, error)
 	First(uuid string) (*model.Task, error)
 	Delete(task *model.Task) error
 	Count() (int64, error)
 	CountUnfinishedByBatch(uuid string) (int64, error)
 	CountAllStatus() (int, int, int, int, int, error)
-	NextQueued(amount int) (*[]model.Task, error)
+	NextQueued(amount int, labels []string, accept func(task *model.Task) (bool, bool)) (*[]model.Task, error)
+	ListPendingDependents(uuid string) (*[]model.Task, error)
+	ListRunningByClient(identifier string) (*[]model.Task, error)
+	ListUUIDs(filter *dto.TaskFilter, uuids []string) ([]string, error)
+	ListBatches(page int, perPage int) ([]string, int64, error)
+	SummarizeBatches(batches []string) (map[string]*dto.BatchSummary, error)
+}
+
+type LogRepository interface {
+	Add(taskLogs []*model.TaskLog) error
+	ListByTask(uuid string, page int, perPage int) (*[]model.TaskLog, int64, error)
+	DeleteByTask(uuid string) error
 }
 
 type Service struct {
 	repository       Repository
+	logRepository    LogRepository
 	presetService    *preset.Service
 	webhookService   *webhook.Service
 	websocketService *websocket.Service
 	ffmpegService    *ffmpeg.Service
 }
 
-func NewService(repository Repository, presetService *preset.Service, webhookService *webhook.Service, websocketService *websocket.Service, ffmpegService *ffmpeg.Service) *Service {
-	return &Service{
+func NewService(repository Repository, logRepository LogRepository, presetService *preset.Service, webhookService *webhook.Service, websocketService *websocket.Service, ffmpegService *ffmpeg.Service) *Service {
+	s := &Service{
 		repository:       repository,
+		logRepository:    logRepository,
 		presetService:    presetService,
 		webhookService:   webhookService,
 		websocketService: websocketService,
 		ffmpegService:    ffmpegService,
 	}
+
+	// receive signals for tasks running on this node
+	websocketService.HandleCluster(websocket.TaskCancel, s.handleCancelSignal)
+	websocketService.HandleCluster(websocket.TaskPause, s.handleSignal(ffmpegService.Pause, dto.Running, s.markPaused))
+	websocketService.HandleCluster(websocket.TaskResume, s.handleSignal(ffmpegService.Resume, dto.Paused, s.markResumed))
+
+	return s
 }
 
 func (s *Service) Get(uuid string) (*model.Task, error) {
@@ -66,8 +94,18 @@ func (s *Service) Get(uuid string) (*model.Task, error) {
 }
 
 func (s *Service) Update(task *model.Task) (*model.Task, error) {
-	task.ClientIdentifier = cfg.GetString("ffmate.identifier")
-	task, err := s.repository.Update(task)
+	// keep the owner of tasks processed by other nodes
+	_, processed := taskQueue.Load(task.UUID)
+	if processed || !isProcessing(task) {
+		task.ClientIdentifier = cfg.GetString("ffmate.identifier")
+	}
+	var err error
+	if processed {
+		// the copy processed by this node does not know about priority edits made since it was claimed
+		task, err = s.repository.UpdateProcessed(task)
+	} else {
+		task, err = s.repository.Update(task)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -75,12 +113,23 @@ func (s *Service) Update(task *model.Task) (*model.Task, error) {
 	s.webhookService.Fire(dto.TaskUpdated, task.ToDTO())
 	s.webhookService.FireDirect(task.Webhooks, dto.TaskUpdated, task.ToDTO())
 	s.websocketService.Broadcast(websocket.TaskUpdated, task.ToDTO())
+	// running tasks report their progress continuously, their batch is summarized less often
+	if task.Status == dto.Running {
+		s.throttleBatch(task.Batch)
+	} else {
+		s.broadcastBatch(task.Batch)
+	}
+
+	if task.Status == dto.DoneError || task.Status == dto.DoneCanceled {
+		s.propagateFailure(task.UUID, task.Status)
+	}
 
 	if task.Batch != "" {
 		switch task.Status {
 		case dto.DoneSuccessful, dto.DoneError, dto.DoneCanceled:
 			c, _ := s.repository.CountUnfinishedByBatch(task.Batch)
 			if c == 0 {
+				batchBroadcasts.Delete(task.Batch)
 				metrics.Gauge("batch.finished").Inc()
 				s.webhookService.Fire(dto.BatchFinished, task.ToDTO())
 			}
@@ -90,7 +139,18 @@ func (s *Service) Update(task *model.Task) (*model.Task, error) {
 	return task, nil
 }
 
-func (s *Service) Cancel(uuid string) (*model.Task, error) {
+// isProcessing reports whether the task is currently processed by a node (including paused processes)
+func isProcessing(task *model.Task) bool {
+	switch task.Status {
+	case dto.Running, dto.PreProcessing, dto.PostProcessing:
+		return true
+	case dto.Paused:
+		return task.StartedAt > 0
+	}
+	return false
+}
+
+func (s *Service) Cancel(uuid string, cleanup bool) (*model.Task, error) {
 	w, err := s.repository.First(uuid)
 	if err != nil {
 		return nil, err
@@ -100,6 +160,14 @@ func (s *Service) Cancel(uuid string) (*model.Task, error) {
 		return nil, errors.New("task for given uuid not found")
 	}
 
+	// stop the process if the task is running on this node, otherwise signal the node that owns it
+	switch w.Status {
+	case dto.Running, dto.PreProcessing, dto.PostProcessing, dto.Paused:
+		if !s.abortTask(uuid, &canceledError{Cleanup: cleanup}) {
+			s.websocketService.NotifyCluster(websocket.TaskCancel, &cancelSignal{UUID: uuid, Cleanup: cleanup})
+		}
+	}
+
 	w.Status = dto.DoneCanceled
 	w.Remaining = -1
 	w.Progress = 100
@@ -126,6 +194,13 @@ func (s *Service) Restart(uuid string) (*model.Task, error) {
 	w.StartedAt = 0
 	w.FinishedAt = 0
 	w.Error = ""
+	w.Attempt = 0
+	w.Attempts = nil
+	w.RetryAt = 0
+
+	if err := s.logRepository.DeleteByTask(w.UUID); err != nil {
+		debug.Log.Error("failed to delete task logs (uuid: %s): %v", uuid, err)
+	}
 
 	metrics.Gauge("task.restarted").Inc()
 	debug.Log.Info("restarted task (uuid: %s)", uuid)
@@ -133,8 +208,15 @@ func (s *Service) Restart(uuid string) (*model.Task, error) {
 	return s.Update(w)
 }
 
-func (s *Service) List(page int, perPage int) (*[]model.Task, int64, error) {
-	return s.repository.List(page, perPage)
+func (s *Service) List(page int, perPage int, filter *dto.TaskFilter) (*[]model.Task, int64, error) {
+	return s.repository.List(page, perPage, filter)
+}
+
+func (s *Service) Logs(uuid string, page int, perPage int) (*[]model.TaskLog, int64, error) {
+	if _, err := s.Get(uuid); err != nil {
+		return nil, 0, err
+	}
+	return s.logRepository.ListByTask(uuid, page, perPage)
 }
 
 func (s *Service) GetBatch(uuid string, page int, perPage int) (*dto.Batch, int64, error) {
@@ -148,23 +230,34 @@ func (s *Service) GetBatch(uuid string, page int, perPage int) (*dto.Batch, int6
 		taskDTOs = append(taskDTOs, task.ToDTO())
 	}
 
+	summaries, err := s.repository.SummarizeBatches([]string{uuid})
+	if err != nil {
+		return nil, count, err
+	}
+
 	return &dto.Batch{
-		UUID:  uuid,
-		Tasks: taskDTOs,
+		UUID:    uuid,
+		Summary: summaries[uuid],
+		Tasks:   taskDTOs,
 	}, count, err
 }
 
+// presetCache holds the presets loaded during batch creation, keyed by batch and then by preset uuid
 var presetCache = sync.Map{}
 
-func (s *Service) Add(newTask *dto.NewTask, source dto.TaskSource, batch string) (*model.Task, error) {
+// build merges the preset into the new task and creates its model without persisting it
+func (s *Service) build(ctx context.Context, newTask *dto.NewTask, source dto.TaskSource, batch string) (*model.Task, error) {
+	var probe *dto.Probe
 	if newTask.Preset != "" {
 		var preset *model.Preset
 		var err error
 
 		// add preset cache for batch creation
+		var presets *sync.Map
 		if batch != "" {
-			p, ok := presetCache.Load(batch)
-			if ok {
+			p, _ := presetCache.LoadOrStore(batch, &sync.Map{})
+			presets = p.(*sync.Map)
+			if p, ok := presets.Load(newTask.Preset); ok {
 				preset = p.(*model.Preset)
 			}
 		}
@@ -174,17 +267,25 @@ func (s *Service) Add(newTask *dto.NewTask, source dto.TaskSource, batch string)
 				return nil, err
 			}
 
-			if preset != nil {
-				presetCache.Store(batch, preset)
+			if preset != nil && presets != nil {
+				presets.Store(newTask.Preset, preset)
 			}
 		}
 
+		if preset.Rules != nil && len(*preset.Rules) > 0 {
+			preset, probe, err = s.selectPreset(ctx, preset, newTask, source)
+			if err != nil {
+				return nil, err
+			}
+			newTask.Preset = preset.UUID
+		}
+
 		newTask.Command = preset.Command
 		if newTask.OutputFile == "" {
 			newTask.OutputFile = preset.OutputFile
 		}
-		if newTask.Priority == 0 {
-			newTask.Priority = preset.Priority
+		if !newTask.Priority.IsPresent() && preset.Priority > 0 {
+			newTask.Priority.Set(preset.Priority)
 		}
 		if preset.PreProcessing != nil && newTask.PreProcessing == nil {
 			newTask.PreProcessing = &dto.NewPrePostProcessing{ScriptPath: preset.PreProcessing.ScriptPath, SidecarPath: preset.PreProcessing.SidecarPath, ImportSidecar: preset.PreProcessing.ImportSidecar}
@@ -193,6 +294,16 @@ func (s *Service) Add(newTask *dto.NewTask, source dto.TaskSource, batch string)
 			newTask.PostProcessing = &dto.NewPrePostProcessing{ScriptPath: preset.PostProcessing.ScriptPath, SidecarPath: preset.PostProcessing.SidecarPath}
 		}
 
+		newTask.Labels = slices.Concat(preset.Labels, newTask.Labels)
+		newTask.Tags = slices.Concat(preset.Tags, newTask.Tags)
+		if newTask.Weight == 0 {
+			newTask.Weight = preset.Weight
+		}
+
+		if preset.Retry != nil && newTask.Retry == nil {
+			newTask.Retry = preset.Retry
+		}
+
 		if preset.Webhooks != nil {
 			if newTask.Webhooks == nil {
 				newTask.Webhooks = preset.Webhooks
@@ -202,17 +313,21 @@ func (s *Service) Add(newTask *dto.NewTask, source dto.TaskSource, batch string)
 		}
 	}
 
-	// filter webhooks so only "task.*" events remain
-	if newTask.Webhooks != nil {
-		filtered := make(dto.DirectWebhooks, 0, len(*newTask.Webhooks))
-		for _, wh := range *newTask.Webhooks {
-			if strings.HasPrefix(string(wh.Event), "task.") {
-				filtered = append(filtered, wh)
-			}
-		}
-		newTask.Webhooks = &filtered
+	if !newTask.Priority.IsPresent() {
+		newTask.Priority.Set(settings.DefaultPriority())
+	}
+
+	if _, err := newTask.Retry.Matcher(); err != nil {
+		return nil, err
+	}
+
+	dependencies, err := s.validateDependencies(newTask.DependsOn)
+	if err != nil {
+		return nil, err
 	}
 
+	newTask.Webhooks = taskWebhooks(newTask.Webhooks)
+
 	task := &model.Task{
 		UUID:             uuid.NewString(),
 		Command:          &dto.RawResolved{Raw: newTask.Command},
@@ -220,63 +335,99 @@ func (s *Service) Add(newTask *dto.NewTask, source dto.TaskSource, batch string)
 		OutputFile:       &dto.RawResolved{Raw: newTask.OutputFile},
 		Metadata:         newTask.Metadata,
 		Name:             newTask.Name,
-		Priority:         newTask.Priority,
+		Preset:           newTask.Preset,
+		Priority:         newTask.Priority.Val,
+		Weight:           newTask.Weight,
+		Tags:             uniqueTags(newTask.Tags),
 		Progress:         0,
 		Source:           source,
 		Status:           dto.Queued,
 		Batch:            batch,
 		Webhooks:         newTask.Webhooks,
+		Retry:            newTask.Retry,
+		ScheduledAt:      newTask.ScheduledAt,
+		Dependencies:     dependencies,
+		Labels:           taskLabels(newTask.Labels),
 		ClientIdentifier: cfg.GetString("ffmate.identifier"),
+		PreProcessing:    prePostProcessing(newTask.PreProcessing),
+		PostProcessing:   prePostProcessing(newTask.PostProcessing),
+		Probe:            probe,
 	}
 
-	if newTask.PreProcessing != nil {
-		task.PreProcessing = &dto.PrePostProcessing{
-			ScriptPath: &dto.RawResolved{
-				Raw:      newTask.PreProcessing.ScriptPath,
-				Resolved: "",
-			},
-			SidecarPath: &dto.RawResolved{
-				Raw:      newTask.PreProcessing.SidecarPath,
-				Resolved: "",
-			},
-			ImportSidecar: newTask.PreProcessing.ImportSidecar,
+	return task, nil
+}
+
+// selectPreset evaluates the rules of the preset against the probed input file, its name and the metadata of the new task
+func (s *Service) selectPreset(ctx context.Context, preset *model.Preset, newTask *dto.NewTask, source dto.TaskSource) (*model.Preset, *dto.Probe, error) {
+	inputFile := s.wildcardReplacer(newTask.InputFile, newTask.InputFile, newTask.OutputFile, source, newTask.Metadata, nil)
+
+	var probe *dto.Probe
+	if inputFile != "" {
+		var err error
+		if probe, err = s.ffmpegService.Probe(ctx, inputFile); err != nil {
+			debug.Task.Debug("failed to probe input file for preset rules (preset: %s): %v", preset.UUID, err)
 		}
 	}
-	if newTask.PostProcessing != nil {
-		task.PostProcessing = &dto.PrePostProcessing{
-			ScriptPath: &dto.RawResolved{
-				Raw:      newTask.PostProcessing.ScriptPath,
-				Resolved: "",
-			},
-			SidecarPath: &dto.RawResolved{
-				Raw:      newTask.PostProcessing.SidecarPath,
-				Resolved: "",
-			},
-		}
+
+	base := filepath.Base(inputFile)
+	selected, err := s.presetService.Select(preset, &dto.PresetRuleSubject{
+		Probe:    probe,
+		Metadata: newTask.Metadata,
+		Source:   source,
+		File: dto.RuleFile{
+			Path:      inputFile,
+			Name:      base,
+			Basename:  strings.TrimSuffix(base, filepath.Ext(base)),
+			Extension: strings.TrimPrefix(filepath.Ext(base), "."),
+			Dir:       filepath.Dir(inputFile),
+		},
+	})
+	return selected, probe, err
+}
+
+// Add creates and queues a new task; the context bounds the probing of its input file for the rules of its preset
+func (s *Service) Add(ctx context.Context, newTask *dto.NewTask, source dto.TaskSource, batch string) (*model.Task, error) {
+	task, err := s.build(ctx, newTask, source, batch)
+	if err != nil {
+		return nil, err
 	}
 
 	w, err := s.repository.Add(task)
+	s.created(w)
+
+	if err == nil && s.failWithDependencies(w) {
+		return s.repository.First(w.UUID)
+	}
+
+	return w, err
+}
+
+// created announces a newly persisted task
+func (s *Service) created(w *model.Task) {
 	debug.Task.Info("created task (uuid: %s)", w.UUID)
 
 	metrics.Gauge("task.created").Inc()
 	s.webhookService.Fire(dto.TaskCreated, w.ToDTO())
 	s.webhookService.FireDirect(w.Webhooks, dto.TaskCreated, w.ToDTO())
 	s.websocketService.Broadcast(websocket.TaskCreated, w.ToDTO())
-
-	return w, err
 }
 
-func (s *Service) AddBatch(newBatch *dto.NewBatch) (*dto.Batch, error) {
+func (s *Service) AddBatch(ctx context.Context, newBatch *dto.NewBatch) (*dto.Batch, error) {
 	batchUUID := uuid.NewString()
 	tasks := []model.Task{}
 	for _, task := range newBatch.Tasks {
-		t, err := s.Add(task, "api", batchUUID)
+		t, err := s.Add(ctx, task, "api", batchUUID)
 		if err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, *t)
 	}
 
+	return s.finalizeBatch(batchUUID, tasks), nil
+}
+
+// finalizeBatch clears the preset cache and announces the created batch
+func (s *Service) finalizeBatch(batchUUID string, tasks []model.Task) *dto.Batch {
 	// clear preset cache
 	presetCache.Delete(batchUUID)
 
@@ -289,12 +440,10 @@ func (s *Service) AddBatch(newBatch *dto.NewBatch) (*dto.Batch, error) {
 	metrics.Gauge("batch.created").Inc()
 	s.webhookService.Fire(dto.BatCreated, taskDTOs)
 
-	batch := &dto.Batch{
+	return &dto.Batch{
 		UUID:  batchUUID,
 		Tasks: taskDTOs,
 	}
-
-	return batch, nil
 }
 
 func (s *Service) Delete(uuid string) error {
@@ -313,12 +462,20 @@ func (s *Service) Delete(uuid string) error {
 		return err
 	}
 
+	if err := s.logRepository.DeleteByTask(uuid); err != nil {
+		debug.Log.Error("failed to delete task logs (uuid: %s): %v", uuid, err)
+	}
+
+	// dependents can never run once their dependency is gone
+	s.propagateFailure(uuid, dto.DoneCanceled)
+
 	debug.Log.Info("deleted task (uuid: %s)", uuid)
 
 	metrics.Gauge("task.deleted").Inc()
 	s.webhookService.Fire(dto.TaskDeleted, w.ToDTO())
 	s.webhookService.FireDirect(w.Webhooks, dto.TaskDeleted, w.ToDTO())
 	s.websocketService.Broadcast(websocket.TaskDeleted, w.ToDTO())
+	s.broadcastBatch(w.Batch)
 
 	return nil
 }
@@ -342,6 +499,9 @@ func (s *Service) ProcessQueue() *Service {
 		}()
 	}
 
+	// recover tasks left running by a previous run of this client
+	s.ReclaimTasks(cfg.GetString("ffmate.identifier"))
+
 	go s.processQueue()
 
 	return s
@@ -371,14 +531,30 @@ func (s *Service) processQueue() {
 			continue
 		}
 
-		taskQueueLength := s.taskQueueLength()
-		var maxConcurrentTasks = cfg.GetInt("ffmate.maxConcurrentTasks")
-		if maxConcurrentTasks == 0 || maxConcurrentTasks <= taskQueueLength {
-			debug.Task.Debug("maximum concurrent tasks reached (tasks: %d/%d)", taskQueueLength, maxConcurrentTasks)
+		if shuttingDown.Load() {
+			debug.Task.Debug("shutting down, skipping processing")
 			continue
 		}
 
-		task, err := s.repository.NextQueued(maxConcurrentTasks - taskQueueLength)
+		if queue := client.QueueStatus(); queue != dto.QueueActive {
+			debug.Task.Debug("queue is %s, skipping processing", queue)
+			continue
+		}
+
+		presetLimits, err := s.presetService.ConcurrencyLimits()
+		if err != nil {
+			debug.Log.Error("failed to receive preset concurrency limits from db: %v", err)
+			continue
+		}
+
+		var maxConcurrentTasks = settings.MaxConcurrentTasks()
+		slots := s.newSlots(maxConcurrentTasks, presetLimits)
+		if slots.free <= 0 {
+			debug.Task.Debug("maximum concurrent tasks reached (slots: %d/%d)", maxConcurrentTasks-slots.free, maxConcurrentTasks)
+			continue
+		}
+
+		task, err := s.repository.NextQueued(slots.free, cfg.GetOrDefault("ffmate.labels", []string{}), slots.claim)
 		if err != nil {
 			debug.Log.Error("failed to receive queued task from db: %v", err)
 			continue
@@ -389,8 +565,9 @@ func (s *Service) processQueue() {
 		}
 
 		for _, t := range *task {
-			ctx := context.Background()
-			taskQueue.Store(t.UUID, ctx)
+			ctx, cancel := context.WithCancelCause(context.Background())
+			claimed := t // keep an unmodified copy for slot accounting
+			taskQueue.Store(t.UUID, &runningTask{ctx: ctx, cancel: cancel, task: &claimed})
 			go s.processNewTask(&t)
 		}
 	}
@@ -400,12 +577,21 @@ func (s *Service) processNewTask(task *model.Task) {
 	debug.Task.Info("processing task (uuid: %s)", task.UUID)
 	defer taskQueue.Delete(task.UUID)
 
+	logger := s.startTaskLogger(task.UUID)
+	defer logger.Close()
+
 	task.StartedAt = time.Now().UnixMilli()
+	task.Attempt++
+
+	s.injectDependencies(task)
 
 	if err := s.runPreProcessing(task); err != nil {
 		s.failTask(task, err)
 		return
 	}
+	if s.checkCanceled(task) {
+		return
+	}
 
 	s.prepareTaskFiles(task)
 
@@ -422,24 +608,41 @@ func (s *Service) processNewTask(task *model.Task) {
 		s.failTask(task, err)
 		return
 	}
+	if s.checkCanceled(task) {
+		return
+	}
 
 	s.finalizeTask(task)
 }
 
 func (s *Service) cancelTask(task *model.Task, err error) {
+	s.cleanupOutput(task, err)
+
 	task.FinishedAt = time.Now().UnixMilli()
 	task.Progress = 100
+	task.Remaining = -1
 	task.Status = dto.DoneCanceled
 	task.Error = err.Error()
-	_, err = s.Update(task)
-	if err != nil {
+	if _, e := s.Update(task); e != nil {
 		debug.Task.Error("failed to update task after cancel (uuid: %s)", task.UUID)
 	}
 	debug.Task.Info("task canceled (uuid: %s): %v", task.UUID, err)
 }
 
 func (s *Service) failTask(task *model.Task, err error) {
+	// a task failing due to its cancellation is not a failure
+	if s.checkCanceled(task) {
+		return
+	}
+
 	task.FinishedAt = time.Now().UnixMilli()
+	s.recordAttempt(task, err)
+
+	if s.shouldRetry(task, err) {
+		s.retryTask(task, err)
+		return
+	}
+
 	task.Progress = 100
 	task.Status = dto.DoneError
 	task.Error = err.Error()
@@ -450,7 +653,8 @@ func (s *Service) failTask(task *model.Task, err error) {
 	debug.Task.Warn("task failed (uuid: %s)", task.UUID)
 }
 
-func (s *Service) taskQueueLength() int {
+// RunningTasks returns the amount of tasks processed by this node (including paused ones)
+func (s *Service) RunningTasks() int {
 	length := 0
 	taskQueue.Range(func(_, _ any) bool {
 		length++
@@ -0,0 +1,143 @@
+package task
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+// Edit changes a task that has not been started yet; the priority of started tasks can be changed until they are finished
+func (s *Service) Edit(uuid string, update *dto.UpdateTask) (*model.Task, error) {
+	w, err := s.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Retry.IsPresent() {
+		if _, err := update.Retry.Val.Matcher(); err != nil {
+			return nil, err
+		}
+	}
+
+	var task *model.Task
+	switch {
+	case w.Status == dto.Queued, w.Status == dto.Paused && w.StartedAt == 0:
+		applyUpdate(w, update)
+		if update.Priority.IsPresent() {
+			w.Priority = update.Priority.Val
+		}
+		// the task must not have been claimed in the meantime
+		task, err = s.repository.UpdateIfStatus(w, w.Status)
+	case w.Status == dto.DoneSuccessful, w.Status == dto.DoneError, w.Status == dto.DoneCanceled:
+		return nil, errors.New("finished tasks can not be edited")
+	default:
+		if !onlyPriority(update) {
+			return nil, errors.New("only the priority of a started task can be changed")
+		}
+		if !update.Priority.IsPresent() {
+			return w, nil
+		}
+		// the processing node owns all other columns, the task must not have finished in the meantime
+		task, err = s.repository.UpdatePriority(w.UUID, w.Status, update.Priority.Val)
+	}
+	if err != nil {
+		debug.Log.Error("failed to edit task (uuid: %s): %v", uuid, err)
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task status changed during the update, please try again")
+	}
+
+	debug.Log.Info("edited task (uuid: %s)", uuid)
+
+	metrics.Gauge("task.updated").Inc()
+	s.updated(task)
+
+	return task, nil
+}
+
+// updated announces a task changed without a full save, e.g. by a conditional update
+func (s *Service) updated(task *model.Task) {
+	s.webhookService.Fire(dto.TaskUpdated, task.ToDTO())
+	s.webhookService.FireDirect(task.Webhooks, dto.TaskUpdated, task.ToDTO())
+	s.websocketService.Broadcast(websocket.TaskUpdated, task.ToDTO())
+	s.broadcastBatch(task.Batch)
+}
+
+// onlyPriority reports whether the update changes nothing but the priority
+func onlyPriority(update *dto.UpdateTask) bool {
+	return !update.Name.IsPresent() && !update.Command.IsPresent() && !update.InputFile.IsPresent() && !update.OutputFile.IsPresent() &&
+		!update.Metadata.IsPresent() && !update.Webhooks.IsPresent() && !update.PreProcessing.IsPresent() && !update.PostProcessing.IsPresent() &&
+		!update.Retry.IsPresent() && !update.Tags.IsPresent() && !update.Weight.IsPresent() && !update.ScheduledAt.IsPresent()
+}
+
+func applyUpdate(task *model.Task, update *dto.UpdateTask) {
+	if update.Name.IsPresent() {
+		task.Name = update.Name.Val
+	}
+	if update.Command.IsPresent() {
+		task.Command = &dto.RawResolved{Raw: update.Command.Val}
+	}
+	if update.InputFile.IsPresent() {
+		task.InputFile = &dto.RawResolved{Raw: update.InputFile.Val}
+	}
+	if update.OutputFile.IsPresent() {
+		task.OutputFile = &dto.RawResolved{Raw: update.OutputFile.Val}
+	}
+	if update.Metadata.IsPresent() {
+		task.Metadata = update.Metadata.Val
+	}
+	if update.Webhooks.IsPresent() {
+		webhooks := taskWebhooks(update.Webhooks.Val)
+		webhooks.KeepSecrets(task.Webhooks)
+		task.Webhooks = webhooks
+	}
+	if update.PreProcessing.IsPresent() {
+		task.PreProcessing = prePostProcessing(update.PreProcessing.Val)
+	}
+	if update.PostProcessing.IsPresent() {
+		task.PostProcessing = prePostProcessing(update.PostProcessing.Val)
+	}
+	if update.Retry.IsPresent() {
+		task.Retry = update.Retry.Val
+	}
+	if update.Tags.IsPresent() {
+		task.Tags = uniqueTags(update.Tags.Val)
+	}
+	if update.Weight.IsPresent() {
+		task.Weight = max(update.Weight.Val, 1)
+	}
+	if update.ScheduledAt.IsPresent() {
+		task.ScheduledAt = update.ScheduledAt.Val
+	}
+}
+
+// taskWebhooks filters the webhooks so only "task.*" events remain
+func taskWebhooks(webhooks *dto.DirectWebhooks) *dto.DirectWebhooks {
+	if webhooks == nil {
+		return nil
+	}
+	filtered := make(dto.DirectWebhooks, 0, len(*webhooks))
+	for _, wh := range *webhooks {
+		if strings.HasPrefix(string(wh.Event), "task.") {
+			filtered = append(filtered, wh)
+		}
+	}
+	return &filtered
+}
+
+func prePostProcessing(processing *dto.NewPrePostProcessing) *dto.PrePostProcessing {
+	if processing == nil {
+		return nil
+	}
+	return &dto.PrePostProcessing{
+		ScriptPath:    &dto.RawResolved{Raw: processing.ScriptPath},
+		SidecarPath:   &dto.RawResolved{Raw: processing.SidecarPath},
+		ImportSidecar: processing.ImportSidecar,
+	}
+}
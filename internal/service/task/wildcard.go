@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,18 +17,18 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/dto"
 )
 
-func (s *Service) wildcardReplacer(input string, inputFile string, outputFile string, source dto.TaskSource, metadata *dto.MetadataMap) string {
+func (s *Service) wildcardReplacer(input string, inputFile string, outputFile string, source dto.TaskSource, metadata *dto.MetadataMap, probe *dto.Probe) string {
 	input = strings.ReplaceAll(input, "${INPUT_FILE}", fmt.Sprintf("\"%s\"", inputFile))
 	input = strings.ReplaceAll(input, "${OUTPUT_FILE}", fmt.Sprintf("\"%s\"", outputFile))
 
 	input = strings.ReplaceAll(input, "${INPUT_FILE_BASE}", filepath.Base(inputFile))
-	input = strings.ReplaceAll(input, "${OUTPUT_FILE_BASE}", filepath.Base(inputFile))
+	input = strings.ReplaceAll(input, "${OUTPUT_FILE_BASE}", filepath.Base(outputFile))
 	input = strings.ReplaceAll(input, "${INPUT_FILE_EXTENSION}", filepath.Ext(filepath.Base(inputFile)))
-	input = strings.ReplaceAll(input, "${OUTPUT_FILE_EXTENSION", filepath.Ext(filepath.Base(outputFile)))
+	input = strings.ReplaceAll(input, "${OUTPUT_FILE_EXTENSION}", filepath.Ext(filepath.Base(outputFile)))
 	input = strings.ReplaceAll(input, "${INPUT_FILE_BASENAME}", strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(filepath.Base(inputFile))))
 	input = strings.ReplaceAll(input, "${OUTPUT_FILE_BASENAME}", strings.TrimSuffix(filepath.Base(outputFile), filepath.Ext(filepath.Base(outputFile))))
 	input = strings.ReplaceAll(input, "${INPUT_FILE_DIR}", filepath.Dir(inputFile))
-	input = strings.ReplaceAll(input, "${OUTPUT_FILE_DIR}", filepath.Dir(inputFile))
+	input = strings.ReplaceAll(input, "${OUTPUT_FILE_DIR}", filepath.Dir(outputFile))
 
 	input = strings.ReplaceAll(input, "${DATE_YEAR}", time.Now().Format("2006"))
 	input = strings.ReplaceAll(input, "${DATE_SHORTYEAR}", time.Now().Format("06"))
@@ -55,23 +56,77 @@ func (s *Service) wildcardReplacer(input string, inputFile string, outputFile st
 
 	input = strings.ReplaceAll(input, "${FFMPEG}", cfg.GetString("ffmate.ffmpeg"))
 
-	// handle metadata wildcard
+	// handle metadata and probe wildcards
 	if metadata != nil {
-		metadataJSON, err := json.Marshal(metadata)
-
-		if err == nil {
-			jsonStr := string(metadataJSON)
-			re := regexp.MustCompile(`\$\{METADATA_([^}]+)\}`)
-			input = re.ReplaceAllStringFunc(input, func(match string) string {
-				path := re.FindStringSubmatch(match)[1]
-				val := gjson.Get(jsonStr, path)
-				if val.Exists() {
-					return val.String()
-				}
-				return ""
-			})
-		}
+		input = replaceJSONWildcards(input, reMetadataWildcard, metadata)
+	}
+	if probe != nil {
+		input = replaceJSONWildcards(input, reProbeWildcard, probe)
 	}
 
 	return input
 }
+
+var (
+	reMetadataWildcard = regexp.MustCompile(`\$\{METADATA_([^}]+)\}`)
+	reProbeWildcard    = regexp.MustCompile(`\$\{PROBE_([^}]+)\}`)
+)
+
+// replaceJSONWildcards replaces the wildcards by the value at their (gjson) path of the marshaled value
+func replaceJSONWildcards(input string, re *regexp.Regexp, value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return input
+	}
+	jsonStr := string(b)
+	return re.ReplaceAllStringFunc(input, func(match string) string {
+		path := re.FindStringSubmatch(match)[1]
+		val := gjson.Get(jsonStr, path)
+		if val.Exists() {
+			return val.String()
+		}
+		return ""
+	})
+}
+
+var wildcards = []string{
+	"INPUT_FILE", "OUTPUT_FILE",
+	"INPUT_FILE_BASE", "OUTPUT_FILE_BASE", "INPUT_FILE_EXTENSION", "OUTPUT_FILE_EXTENSION", "INPUT_FILE_BASENAME", "OUTPUT_FILE_BASENAME", "INPUT_FILE_DIR", "OUTPUT_FILE_DIR",
+	"DATE_YEAR", "DATE_SHORTYEAR", "DATE_MONTH", "DATE_DAY", "DATE_WEEK",
+	"TIME_HOUR", "TIME_MINUTE", "TIME_SECOND",
+	"TIMESTAMP_SECONDS", "TIMESTAMP_MILLISECONDS", "TIMESTAMP_MICROSECONDS", "TIMESTAMP_NANOSECONDS",
+	"OS_NAME", "OS_ARCH", "SOURCE", "UUID", "FFMPEG",
+}
+
+var reWildcard = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// unknownWildcards returns all wildcards of the input that are neither known nor point to existing metadata or probe values
+func unknownWildcards(input string, metadata *dto.MetadataMap, probe *dto.Probe) []string {
+	var metadataJSON, probeJSON string
+	if metadata != nil {
+		if b, err := json.Marshal(metadata); err == nil {
+			metadataJSON = string(b)
+		}
+	}
+	if probe != nil {
+		if b, err := json.Marshal(probe); err == nil {
+			probeJSON = string(b)
+		}
+	}
+
+	unknown := []string{}
+	for _, match := range reWildcard.FindAllStringSubmatch(input, -1) {
+		name := match[1]
+		if slices.Contains(wildcards, name) {
+			continue
+		}
+		if path, ok := strings.CutPrefix(name, "METADATA_"); ok && gjson.Get(metadataJSON, path).Exists() {
+			continue
+		}
+		if path, ok := strings.CutPrefix(name, "PROBE_"); ok && gjson.Get(probeJSON, path).Exists() {
+			continue
+		}
+		unknown = append(unknown, match[0])
+	}
+	return unknown
+}
@@ -0,0 +1,29 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+func TestWildcardReplacerFileWildcards(t *testing.T) {
+	cfg.Set("ffmate.ffmpeg", "ffmpeg")
+	s := &Service{}
+
+	for input, expected := range map[string]string{
+		"${INPUT_FILE}":            `"/in/source.mov"`,
+		"${OUTPUT_FILE}":           `"/out/target.mp4"`,
+		"${INPUT_FILE_BASE}":       "source.mov",
+		"${OUTPUT_FILE_BASE}":      "target.mp4",
+		"${INPUT_FILE_EXTENSION}":  ".mov",
+		"${OUTPUT_FILE_EXTENSION}": ".mp4",
+		"${INPUT_FILE_BASENAME}":   "source",
+		"${OUTPUT_FILE_BASENAME}":  "target",
+		"${INPUT_FILE_DIR}":        "/in",
+		"${OUTPUT_FILE_DIR}":       "/out",
+	} {
+		assert.Equal(t, expected, s.wildcardReplacer(input, "/in/source.mov", "/out/target.mp4", dto.API, nil, nil), input)
+	}
+}
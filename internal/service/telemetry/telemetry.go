@@ -17,6 +17,7 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/metrics"
 	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/settings"
 	"gorm.io/gorm"
 	"goyave.dev/goyave/v5/config"
 )
@@ -68,7 +69,7 @@ func (s *Service) collectTelemetry(runtimeDuration time.Time, isShuttingDown boo
 		"session":         cfg.GetString("ffmate.session"),
 		"config": map[string]any{
 			"isTray":             cfg.GetBool("ffmate.isTray"),
-			"maxConcurrentTasks": cfg.GetInt("ffmate.maxConcurrentTasks"),
+			"maxConcurrentTasks": settings.MaxConcurrentTasks(),
 			"debug":              cfg.GetString("ffmate.debug"),
 			"isDocker":           cfg.GetBool("ffmate.isDocker"),
 			"isCluster":          cfg.GetBool("ffmate.isCluster"),
@@ -1,6 +1,7 @@
 package watchfolder
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -355,7 +356,7 @@ func (s *Service) createTask(path string, watchfolder *model.Watchfolder) {
 	}
 
 	// add new Task
-	_, err = s.taskService.Add(task, "watchfolder", "")
+	_, err = s.taskService.Add(context.Background(), task, "watchfolder", "")
 	if err != nil {
 		debug.Log.Error("failed to create task for watchfolder (uuid: %s) file: %s: %v", watchfolder.UUID, path, err)
 		return
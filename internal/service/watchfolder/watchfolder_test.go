@@ -15,7 +15,9 @@ import (
 	"github.com/welovemedia/ffmate/v2/internal/service"
 	clientService "github.com/welovemedia/ffmate/v2/internal/service/client"
 	"github.com/welovemedia/ffmate/v2/internal/service/ffmpeg"
+	janitorService "github.com/welovemedia/ffmate/v2/internal/service/janitor"
 	presetService "github.com/welovemedia/ffmate/v2/internal/service/preset"
+	scheduleService "github.com/welovemedia/ffmate/v2/internal/service/schedule"
 	settingsSvc "github.com/welovemedia/ffmate/v2/internal/service/settings"
 	taskService "github.com/welovemedia/ffmate/v2/internal/service/task"
 	"github.com/welovemedia/ffmate/v2/internal/service/telemetry"
@@ -32,21 +34,27 @@ func prepare(t *testing.T) (*testutil.TestServer, *Service) {
 	presetRepository := (&repository.Preset{DB: server.DB()}).Setup()
 	webhookRepository := (&repository.Webhook{DB: server.DB()}).Setup()
 	webhookExecutionRepository := (&repository.WebhookExecution{DB: server.DB()}).Setup()
+	webhookDeliveryRepository := (&repository.WebhookDelivery{DB: server.DB()}).Setup()
 	watchfolderRepository := (&repository.Watchfolder{DB: server.DB()}).Setup()
+	scheduleRepository := (&repository.Schedule{DB: server.DB()}).Setup()
 	taskRepository := (&repository.Task{DB: server.DB()}).Setup()
+	taskLogRepository := (&repository.TaskLog{DB: server.DB()}).Setup()
 	clientRepository := (&repository.Client{DB: server.DB()}).Setup()
+	janitorRepository := &repository.Janitor{DB: server.DB()}
 	settingsRepository := (&repository.Settings{DB: server.DB()}).Setup()
 
 	// setup and register services
-	settingsService := settingsSvc.NewService(settingsRepository)
 	telemetrySvc := telemetry.NewService(server.Config(), server.DB())
 	ffmpegSvc := ffmpeg.NewService()
 	websocketSvc := websocketService.NewService(server.DB())
-	webhookSvc := webhookService.NewService(webhookRepository, webhookExecutionRepository, server.Config(), websocketSvc)
+	settingsService := settingsSvc.NewService(settingsRepository, websocketSvc)
+	webhookSvc := webhookService.NewService(webhookRepository, webhookExecutionRepository, webhookDeliveryRepository, server.Config(), websocketSvc).ProcessQueue()
 	presetSvc := presetService.NewService(presetRepository, webhookSvc, websocketSvc)
-	taskSvc := taskService.NewService(taskRepository, presetSvc, webhookSvc, websocketSvc, ffmpegSvc).ProcessQueue()
+	taskSvc := taskService.NewService(taskRepository, taskLogRepository, presetSvc, webhookSvc, websocketSvc, ffmpegSvc).ProcessQueue()
 	watchfolderSvc := NewService(watchfolderRepository, webhookSvc, websocketSvc, taskSvc)
-	clientSvc := clientService.NewService(clientRepository, "test-1.0.0", websocketSvc)
+	scheduleSvc := scheduleService.NewService(scheduleRepository, webhookSvc, websocketSvc, taskSvc)
+	janitorSvc := janitorService.NewService(janitorRepository)
+	clientSvc := clientService.NewService(clientRepository, "test-1.0.0", websocketSvc, webhookSvc, taskSvc)
 	for _, svc := range map[string]goyave.Service{
 		service.FFMpeg:      ffmpegSvc,
 		service.Telemetry:   telemetrySvc,
@@ -54,8 +62,10 @@ func prepare(t *testing.T) (*testutil.TestServer, *Service) {
 		service.Webhook:     webhookSvc,
 		service.Preset:      presetSvc,
 		service.Watchfolder: watchfolderSvc,
+		service.Schedule:    scheduleSvc,
 		service.Task:        taskSvc,
 		service.Settings:    settingsService,
+		service.Janitor:     janitorSvc,
 		service.Client:      clientSvc,
 	} {
 		server.RegisterService(svc)
@@ -190,7 +200,7 @@ func TestCreateTask_CreatesTaskWithMetadata(t *testing.T) {
 
 	// Query created tasks
 	taskRepo := &repository.Task{DB: server.DB()}
-	tasks, _, err := taskRepo.List(0, 10)
+	tasks, _, err := taskRepo.List(0, 10, nil)
 	assert.NoError(t, err, "listing tasks should not error")
 	if assert.NotNil(t, tasks, "tasks should not be nil") && assert.GreaterOrEqual(t, len(*tasks), 1, "at least one task should exist") {
 		created := (*tasks)[0]
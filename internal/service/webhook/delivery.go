@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+	"github.com/welovemedia/ffmate/v2/internal/service/websocket"
+)
+
+const (
+	defaultTimeout     = 10
+	defaultMaxAttempts = 5
+)
+
+var (
+	// RetryBaseDelay is doubled for every failed attempt, up to RetryMaxDelay
+	RetryBaseDelay = 5 * time.Second
+	RetryMaxDelay  = time.Hour
+
+	// ClaimTimeout is the time after which deliveries claimed by an unresponsive client are claimed again
+	ClaimTimeout = 5 * time.Minute
+)
+
+// ProcessQueue releases deliveries left claimed by a previous run of this client and starts delivering due deliveries
+func (s *Service) ProcessQueue() *Service {
+	identifier := cfg.GetString("ffmate.identifier")
+	s.releaseClaimed(identifier)
+
+	go func() {
+		for {
+			time.Sleep(1 * time.Second)
+			s.processQueue(identifier)
+		}
+	}()
+
+	return s
+}
+
+// releaseClaimed puts the deliveries claimed by a previous run of this client back into the queue one by one
+func (s *Service) releaseClaimed(identifier string) {
+	deliveries, err := s.deliveryRepository.Claimed(identifier)
+	if err != nil {
+		debug.Log.Error("failed to list claimed webhook deliveries: %v", err)
+		return
+	}
+
+	released := 0
+	for _, delivery := range *deliveries {
+		ok, err := s.deliveryRepository.Release(delivery.UUID, delivery.ClaimedAt)
+		if err != nil {
+			debug.Log.Error("failed to release claimed webhook delivery (uuid: %s): %v", delivery.UUID, err)
+			continue
+		}
+		if ok {
+			released++
+		}
+	}
+	if released > 0 {
+		debug.Webhook.Info("released %d webhook deliveries claimed by a previous run", released)
+	}
+}
+
+func (s *Service) processQueue(identifier string) {
+	deliveries, err := s.deliveryRepository.NextDue(10, identifier, time.Now().Add(-ClaimTimeout).UnixMilli())
+	if err != nil {
+		debug.Log.Error("failed to claim webhook deliveries: %v", err)
+		return
+	}
+	if deliveries == nil {
+		return
+	}
+	for i := range *deliveries {
+		go s.deliver(&(*deliveries)[i])
+	}
+}
+
+// enqueue persists the rendered webhook call, claimed by this client so it can be delivered right away
+func (s *Service) enqueue(webhook *model.Webhook, webhookUUID string, data any) *model.WebhookDelivery {
+	b, err := payload(webhook, data)
+	if err != nil {
+		debug.Log.Error("failed to fire webhook due to rendering the payload for event '%s' (url: %s): %v", webhook.Event, webhook.URL, err)
+		return nil
+	}
+
+	delivery := &model.WebhookDelivery{
+		UUID:          uuid.NewString(),
+		Webhook:       webhookUUID,
+		Task:          taskUUID(data),
+		Event:         webhook.Event,
+		URL:           webhook.URL,
+		Headers:       webhook.Headers,
+		Secret:        webhook.Secret,
+		Body:          string(b),
+		ContentType:   contentType(webhook),
+		Status:        dto.DeliveryDelivering,
+		ClaimedBy:     cfg.GetString("ffmate.identifier"),
+		ClaimedAt:     time.Now().UnixMilli(),
+		NextAttemptAt: time.Now().UnixMilli(),
+		MaxAttempts:   webhook.MaxAttempts,
+		Timeout:       webhook.Timeout,
+	}
+	if delivery.MaxAttempts == 0 {
+		delivery.MaxAttempts = defaultMaxAttempts
+	}
+	if delivery.Timeout == 0 {
+		delivery.Timeout = defaultTimeout
+	}
+
+	if _, err := s.deliveryRepository.Add(delivery); err != nil {
+		debug.Log.Error("failed to queue webhook for event '%s' (url: %s): %v", webhook.Event, webhook.URL, err)
+		return nil
+	}
+	return delivery
+}
+
+// deliver sends a claimed delivery once; it is removed on a 2xx response, otherwise retried with exponential backoff until it is dead-lettered
+func (s *Service) deliver(delivery *model.WebhookDelivery) {
+	delivery.Attempts++
+	execution, err := s.send(delivery)
+
+	delivery.ClaimedBy = ""
+	delivery.ClaimedAt = 0
+	delivery.ResponseStatus = 0
+	if execution != nil && execution.Response != nil {
+		delivery.ResponseStatus = execution.Response.Status
+	}
+
+	if err == nil {
+		debug.Webhook.Debug("delivered webhook for event '%s' (uuid: %s)", delivery.Event, delivery.UUID)
+		if err := s.deliveryRepository.Delete(delivery); err != nil {
+			debug.Log.Error("failed to remove delivered webhook from queue (uuid: %s): %v", delivery.UUID, err)
+		}
+		return
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= delivery.MaxAttempts {
+		delivery.Status = dto.DeliveryDeadLetter
+		metrics.Gauge("webhook.deadLettered").Inc()
+		debug.Log.Error("failed to deliver webhook for event '%s' (uuid: %s) after %d attempts: %v", delivery.Event, delivery.UUID, delivery.Attempts, err)
+	} else {
+		delivery.Status = dto.DeliveryPending
+		delivery.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts)).UnixMilli()
+		metrics.Gauge("webhook.retried").Inc()
+		debug.Webhook.Debug("failed to deliver webhook for event '%s' (uuid: %s), retrying: %v", delivery.Event, delivery.UUID, err)
+	}
+
+	if _, err := s.deliveryRepository.Update(delivery); err != nil {
+		debug.Log.Error("failed to update webhook delivery (uuid: %s): %v", delivery.UUID, err)
+	}
+}
+
+// taskUUID returns the uuid of the task an event was fired for, if any
+func taskUUID(data any) string {
+	if task, ok := data.(*dto.Task); ok && task != nil {
+		return task.UUID
+	}
+	return ""
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts
+func backoff(attempts uint) time.Duration {
+	delay := time.Duration(float64(RetryBaseDelay) * math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > RetryMaxDelay {
+		return RetryMaxDelay
+	}
+	return delay
+}
+
+// send posts the delivery and returns the recorded execution; non-2xx responses are returned as error
+func (s *Service) send(delivery *model.WebhookDelivery) (*model.WebhookExecution, error) {
+	body := []byte(delivery.Body)
+	req, err := http.NewRequest("POST", delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %v", err)
+	}
+	req.Header.Add("Content-Type", delivery.ContentType)
+	req.Header.Add("User-Agent", s.config.GetString("app.name")+"/"+s.config.GetString("app.version"))
+	for key, value := range delivery.Headers {
+		req.Header.Set(key, value)
+	}
+	if delivery.Secret != "" {
+		Sign(req.Header, delivery.Secret, body, time.Now())
+	}
+
+	client := &http.Client{Timeout: time.Duration(delivery.Timeout) * time.Second}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return s.handleWebhookExecution(delivery, req, nil, nil, time.Since(start), err), err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return s.handleWebhookExecution(delivery, req, resp, respBody, time.Since(start), nil), err
+}
+
+// handleWebhookExecution records a single attempt of a delivery
+func (s *Service) handleWebhookExecution(delivery *model.WebhookDelivery, req *http.Request, resp *http.Response, respBody []byte, duration time.Duration, requestErr error) *model.WebhookExecution {
+	execution := &model.WebhookExecution{
+		UUID:     uuid.NewString(),
+		Webhook:  delivery.Webhook,
+		Task:     delivery.Task,
+		Delivery: delivery.UUID,
+		Duration: duration.Milliseconds(),
+		Attempt:  delivery.Attempts,
+		Event:    delivery.Event,
+		URL:      delivery.URL,
+		Request: &dto.WebhookRequest{
+			Headers: maskRequestHeaders(req.Header, delivery.Headers),
+			Body:    delivery.Body,
+		},
+	}
+	if resp != nil {
+		execution.Response = &dto.WebhookResponse{
+			Status:  resp.StatusCode,
+			Headers: resp.Header,
+			Body:    string(respBody),
+		}
+		execution.ResponseStatus = resp.StatusCode
+	}
+	if requestErr != nil {
+		execution.Error = requestErr.Error()
+	}
+
+	w, err := s.executionRepository.Add(execution)
+	if err != nil {
+		debug.Log.Error("failed to create webhook execution for event '%s': %v", delivery.Event, err)
+	} else {
+		debug.Webhook.Debug("created new webhook execution for event '%s'", delivery.Event)
+		s.websocketService.Broadcast(websocket.WebhookExecutionCreated, w.ToDTO())
+	}
+	return execution
+}
+
+// ListDeliveries lists the queued and dead-lettered deliveries
+func (s *Service) ListDeliveries(page int, perPage int, status []dto.WebhookDeliveryStatus) (*[]model.WebhookDelivery, int64, error) {
+	return s.deliveryRepository.List(page, perPage, status)
+}
+
+// maskRequestHeaders returns a copy of the request headers with masked values of the custom headers
+func maskRequestHeaders(header http.Header, custom map[string]string) http.Header {
+	masked := header.Clone()
+	for key := range custom {
+		masked.Set(key, dto.MaskedHeader)
+	}
+	return masked
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/debug"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/metrics"
+)
+
+// Redeliver resends the recorded payload of an execution once. It is signed again if the secret is still known,
+// and a dead-lettered delivery is removed from the queue if the redelivery succeeds.
+func (s *Service) Redeliver(uuid string) (*model.WebhookExecution, error) {
+	execution, err := s.executionRepository.First(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if execution == nil || execution.Request == nil {
+		return nil, errors.New("webhook execution for given uuid not found")
+	}
+
+	delivery := &model.WebhookDelivery{
+		UUID:        execution.Delivery,
+		Webhook:     execution.Webhook,
+		Task:        execution.Task,
+		Event:       execution.Event,
+		URL:         execution.URL,
+		Body:        execution.Request.Body,
+		ContentType: http.Header(execution.Request.Headers).Get("Content-Type"),
+		Headers:     map[string]string{},
+		Timeout:     defaultTimeout,
+		Attempts:    execution.Attempt,
+	}
+	for key, values := range execution.Request.Headers {
+		switch http.CanonicalHeaderKey(key) {
+		case "Content-Type", "User-Agent", "Content-Length", "Accept-Encoding", SignatureHeader, TimestampHeader:
+			continue
+		}
+		// custom headers are recorded masked, their values are restored from the queued delivery or the webhook below
+		if value := strings.Join(values, ", "); value != dto.MaskedHeader {
+			delivery.Headers[key] = value
+		}
+	}
+
+	// the queued delivery is kept for dead-lettered and pending deliveries only
+	queued, err := s.deliveryRepository.First(execution.Delivery)
+	if err != nil {
+		return nil, err
+	}
+	if queued != nil {
+		delivery.Secret = queued.Secret
+		delivery.Timeout = queued.Timeout
+		delivery.Attempts = queued.Attempts
+		maps.Copy(delivery.Headers, queued.Headers)
+	}
+	if (delivery.Secret == "" || queued == nil) && execution.Webhook != "" {
+		if webhook, err := s.repository.First(execution.Webhook); err == nil && webhook != nil {
+			if delivery.Secret == "" {
+				delivery.Secret = webhook.Secret
+			}
+			if queued == nil {
+				maps.Copy(delivery.Headers, webhook.Headers)
+			}
+		}
+	}
+
+	delivery.Attempts++
+	result, err := s.send(delivery)
+	if result == nil {
+		return nil, err
+	}
+	metrics.Gauge("webhook.redelivered").Inc()
+	debug.Webhook.Debug("redelivered webhook execution for event '%s' (uuid: %s)", execution.Event, uuid)
+
+	if err == nil && queued != nil && queued.Status == dto.DeliveryDeadLetter {
+		if err := s.deliveryRepository.Delete(queued); err != nil {
+			debug.Log.Error("failed to remove redelivered webhook from queue (uuid: %s): %v", queued.UUID, err)
+		}
+	}
+	return result, nil
+}
+
+// Test sends a synthetic event with sample data to the webhook once
+func (s *Service) Test(uuid string) (*model.WebhookExecution, error) {
+	webhook, err := s.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := payload(webhook, sampleData(webhook))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := webhook.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	execution, _ := s.send(&model.WebhookDelivery{
+		Webhook:     webhook.UUID,
+		Event:       webhook.Event,
+		URL:         webhook.URL,
+		Headers:     webhook.Headers,
+		Secret:      webhook.Secret,
+		Body:        string(b),
+		ContentType: contentType(webhook),
+		Timeout:     timeout,
+		Attempts:    1,
+	})
+	if execution == nil {
+		return nil, errors.New("failed to create http request")
+	}
+	metrics.Gauge("webhook.tested").Inc()
+	return execution, nil
+}
+
+// sampleData returns a synthetic payload matching the event of the webhook
+func sampleData(webhook *model.Webhook) any {
+	now := time.Now()
+	switch event := string(webhook.Event); {
+	case strings.HasPrefix(event, "task."):
+		return &dto.Task{
+			UUID:       uuid.NewString(),
+			Name:       "Test task",
+			Command:    &dto.RawResolved{Raw: "-y -i ${INPUT_FILE} ${OUTPUT_FILE}", Resolved: "-y -i /tmp/input.mp4 /tmp/output.mp4"},
+			InputFile:  &dto.RawResolved{Raw: "/tmp/input.mp4", Resolved: "/tmp/input.mp4"},
+			OutputFile: &dto.RawResolved{Raw: "/tmp/output.mp4", Resolved: "/tmp/output.mp4"},
+			Status:     dto.DoneSuccessful,
+			Source:     dto.API,
+			Progress:   100,
+			Remaining:  -1,
+			StartedAt:  now.Add(-time.Minute).UnixMilli(),
+			FinishedAt: now.UnixMilli(),
+			CreatedAt:  now.Add(-time.Minute).UnixMilli(),
+			UpdatedAt:  now.UnixMilli(),
+		}
+	case strings.HasPrefix(event, "batch."):
+		return &dto.Batch{UUID: uuid.NewString()}
+	case strings.HasPrefix(event, "preset."):
+		return &dto.Preset{UUID: uuid.NewString(), Name: "Test preset", Command: "-y -i ${INPUT_FILE} ${OUTPUT_FILE}", CreatedAt: now, UpdatedAt: now}
+	default:
+		return webhook.ToDTO()
+	}
+}
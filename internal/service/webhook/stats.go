@@ -0,0 +1,44 @@
+package webhook
+
+import "github.com/welovemedia/ffmate/v2/internal/dto"
+
+// Stats aggregates the recorded executions of a global webhook
+func (s *Service) Stats(uuid string) (*dto.WebhookStats, error) {
+	webhook, err := s.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	total, successful, err := s.executionRepository.CountByWebhook(webhook.UUID)
+	if err != nil {
+		return nil, err
+	}
+	p50, err := s.executionRepository.DurationPercentile(webhook.UUID, 50)
+	if err != nil {
+		return nil, err
+	}
+	p95, err := s.executionRepository.DurationPercentile(webhook.UUID, 95)
+	if err != nil {
+		return nil, err
+	}
+	lastFailure, err := s.executionRepository.LastFailure(webhook.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dto.WebhookStats{
+		Webhook:    webhook.UUID,
+		Executions: total,
+		Successful: successful,
+		Failed:     total - successful,
+		LatencyP50: p50,
+		LatencyP95: p95,
+	}
+	if total > 0 {
+		stats.SuccessRate = float64(successful) / float64(total)
+	}
+	if lastFailure != nil {
+		stats.LastFailure = lastFailure.ToDTO()
+	}
+	return stats, nil
+}
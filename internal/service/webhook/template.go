@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+)
+
+var templateFuncs = template.FuncMap{
+	// json encodes the value, e.g. to safely embed strings into a json body
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseTemplate parses a payload template; it has access to ".event" and ".data" using the json field names of the payload
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// payload renders the body of the webhook, defaulting to {"event": ..., "data": ...}
+func payload(webhook *model.Webhook, data any) ([]byte, error) {
+	msg := map[string]any{
+		"event": webhook.Event,
+		"data":  data,
+	}
+	b, err := json.Marshal(&msg)
+	if err != nil || webhook.Template == "" {
+		return b, err
+	}
+
+	tmpl, err := ParseTemplate(webhook.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	// expose the json representation so templates use the same field names as the default payload;
+	// numbers are kept as json.Number so integers like timestamps are not rendered in float notation
+	var values map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// contentType returns the content type of the webhook, defaulting to json
+func contentType(webhook *model.Webhook) string {
+	if webhook.ContentType != "" {
+		return webhook.ContentType
+	}
+	return "application/json"
+}
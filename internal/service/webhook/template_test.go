@@ -0,0 +1,17 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+)
+
+func TestPayloadTemplateNumbers(t *testing.T) {
+	webhook := &model.Webhook{Event: dto.TaskCreated, Template: `{"createdAt": {{.data.createdAt}}, "progress": {{json .data.progress}}}`}
+
+	b, err := payload(webhook, map[string]any{"createdAt": int64(1760000000123), "progress": 12.5})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"createdAt": 1760000000123, "progress": 12.5}`, string(b))
+}
@@ -1,11 +1,12 @@
 package webhook
 
 import (
-	"bytes"
-	"encoding/json"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,21 +30,38 @@ type Repository interface {
 }
 
 type ExecutionRepository interface {
-	List(page int, perPage int) (*[]model.WebhookExecution, int64, error)
+	List(page int, perPage int, filter *dto.WebhookExecutionFilter) (*[]model.WebhookExecution, int64, error)
 	Add(webhookExecution *model.WebhookExecution) (*model.WebhookExecution, error)
+	First(uuid string) (*model.WebhookExecution, error)
+	CountByWebhook(webhook string) (int64, int64, error)
+	DurationPercentile(webhook string, p float64) (int64, error)
+	LastFailure(webhook string) (*model.WebhookExecution, error)
+}
+
+type DeliveryRepository interface {
+	List(page int, perPage int, status []dto.WebhookDeliveryStatus) (*[]model.WebhookDelivery, int64, error)
+	Add(delivery *model.WebhookDelivery) (*model.WebhookDelivery, error)
+	Update(delivery *model.WebhookDelivery) (*model.WebhookDelivery, error)
+	Delete(delivery *model.WebhookDelivery) error
+	First(uuid string) (*model.WebhookDelivery, error)
+	NextDue(amount int, identifier string, staleBefore int64) (*[]model.WebhookDelivery, error)
+	Claimed(identifier string) (*[]model.WebhookDelivery, error)
+	Release(uuid string, claimedAt int64) (bool, error)
 }
 
 type Service struct {
 	repository          Repository
 	executionRepository ExecutionRepository
+	deliveryRepository  DeliveryRepository
 	config              *config.Config
 	websocketService    *websocket.Service
 }
 
-func NewService(repository Repository, executionRepository ExecutionRepository, config *config.Config, websocketService *websocket.Service) *Service {
+func NewService(repository Repository, executionRepository ExecutionRepository, deliveryRepository DeliveryRepository, config *config.Config, websocketService *websocket.Service) *Service {
 	return &Service{
 		repository:          repository,
 		executionRepository: executionRepository,
+		deliveryRepository:  deliveryRepository,
 		config:              config,
 		websocketService:    websocketService,
 	}
@@ -66,12 +84,12 @@ func (s *Service) List(page int, perPage int) (*[]model.Webhook, int64, error) {
 	return s.repository.List(page, perPage)
 }
 
-func (s *Service) ListExecutions(page int, perPage int) (*[]model.WebhookExecution, int64, error) {
-	return s.executionRepository.List(page, perPage)
+func (s *Service) ListExecutions(page int, perPage int, filter *dto.WebhookExecutionFilter) (*[]model.WebhookExecution, int64, error) {
+	return s.executionRepository.List(page, perPage, filter)
 }
 
 func (s *Service) Add(newWebhook *dto.NewWebhook) (*model.Webhook, error) {
-	w, err := s.repository.Add(&model.Webhook{UUID: uuid.NewString(), Event: newWebhook.Event, URL: newWebhook.URL})
+	w, err := s.repository.Add(&model.Webhook{UUID: uuid.NewString(), Event: newWebhook.Event, URL: newWebhook.URL, Headers: newWebhook.Headers, Secret: newWebhook.Secret, Template: newWebhook.Template, ContentType: newWebhook.ContentType, Timeout: newWebhook.Timeout, MaxAttempts: newWebhook.MaxAttempts})
 	debug.Log.Info("created webhook (uuid: %s)", w.UUID)
 
 	metrics.Gauge("webhook.created").Inc()
@@ -93,6 +111,17 @@ func (s *Service) Update(uuid string, newWebhook *dto.NewWebhook) (*model.Webhoo
 
 	w.Event = newWebhook.Event
 	w.URL = newWebhook.URL
+	// masked header values are kept unchanged
+	dto.KeepHeaders(newWebhook.Headers, w.Headers)
+	w.Headers = newWebhook.Headers
+	w.Template = newWebhook.Template
+	w.ContentType = newWebhook.ContentType
+	w.Timeout = newWebhook.Timeout
+	w.MaxAttempts = newWebhook.MaxAttempts
+	// the secret is write-only, keep the current one if none is given
+	if newWebhook.Secret != "" {
+		w.Secret = newWebhook.Secret
+	}
 
 	w, err = s.repository.Update(w)
 	if err != nil {
@@ -137,7 +166,9 @@ func (s *Service) Delete(uuid string) error {
 func (s *Service) Fire(event dto.WebhookEvent, data any) {
 	webhooks, _ := s.repository.ListAllByEvent(event)
 	for _, webhook := range *webhooks {
-		go s.fireWebhook(&webhook, data, s.handleWebhookExecution)
+		if delivery := s.enqueue(&webhook, webhook.UUID, data); delivery != nil {
+			go s.deliver(delivery)
+		}
 		metrics.Gauge("webhook.executed").Inc()
 	}
 }
@@ -146,7 +177,9 @@ func (s *Service) Fire(event dto.WebhookEvent, data any) {
 func (s *Service) FireInRoutine(event dto.WebhookEvent, data any) {
 	webhooks, _ := s.repository.ListAllByEvent(event)
 	for _, webhook := range *webhooks {
-		s.fireWebhook(&webhook, data, s.handleWebhookExecution)
+		if delivery := s.enqueue(&webhook, webhook.UUID, data); delivery != nil {
+			s.deliver(delivery)
+		}
 		metrics.Gauge("webhook.executed").Inc()
 	}
 }
@@ -157,101 +190,28 @@ func (s *Service) FireDirect(webhooks *dto.DirectWebhooks, event dto.WebhookEven
 	}
 	for _, webhook := range *webhooks {
 		if webhook.Event == event {
-			go s.fireWebhook(&model.Webhook{UUID: uuid.NewString(), Event: webhook.Event, URL: webhook.URL}, data, s.handleWebhookExecution)
+			w := &model.Webhook{Event: webhook.Event, URL: webhook.URL, Headers: webhook.Headers, Secret: webhook.Secret, Template: webhook.Template, ContentType: webhook.ContentType, Timeout: webhook.Timeout, MaxAttempts: webhook.MaxAttempts}
+			if delivery := s.enqueue(w, "", data); delivery != nil {
+				go s.deliver(delivery)
+			}
 			metrics.Gauge("webhook.executed.direct").Inc()
 		}
 	}
 }
 
-func (s *Service) handleWebhookExecution(event dto.WebhookEvent, url string, req *http.Request, resp *http.Response) {
-	// Read request body
-	var reqBodyStr string
-	if req.Body != nil {
-		bodyBytes, _ := io.ReadAll(req.Body)
-		reqBodyStr = string(bodyBytes)
-		req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-	}
-
-	// Read response body
-	var respBodyStr string
-	if resp.Body != nil {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		respBodyStr = string(bodyBytes)
-		resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-	}
-
-	request := &dto.WebhookRequest{
-		Headers: req.Header,
-		Body:    reqBodyStr,
-	}
-
-	response := &dto.WebhookResponse{
-		Status:  resp.StatusCode,
-		Headers: resp.Header,
-		Body:    respBodyStr,
-	}
-
-	w, err := s.executionRepository.Add(&model.WebhookExecution{
-		UUID:     uuid.NewString(),
-		Event:    event,
-		URL:      url,
-		Request:  request,
-		Response: response,
-	})
-	if err != nil {
-		debug.Log.Error("failed to create webhook execution for event '%s': %v", event, err)
-	} else {
-		debug.Webhook.Debug("created new webhook execution for event '%s'", event)
-		s.websocketService.Broadcast(websocket.WebhookExecutionCreated, w.ToDTO())
-	}
-}
-
-func (s *Service) fireWebhook(webhook *model.Webhook, data any, callback func(event dto.WebhookEvent, url string, req *http.Request, resp *http.Response)) {
-	msg := map[string]any{
-		"event": webhook.Event,
-		"data":  data,
-	}
-	b, err := json.Marshal(&msg)
-	if err != nil {
-		debug.Log.Error("failed to fire webhook due to marshaling for event '%s' (uuid: %s): %v", webhook.Event, webhook.UUID, err)
-		return
-	}
-
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", webhook.URL, bytes.NewBuffer(b))
-	if err != nil {
-		debug.Log.Error("failed to create http request", err)
-		return
-	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("User-Agent", s.config.GetString("app.name")+"/"+s.config.GetString("app.version"))
-
-	retryDelays := []time.Duration{
-		3 * time.Second,
-		5 * time.Second,
-		10 * time.Second,
-	}
-
-	var resp *http.Response
-	for try := 0; try <= len(retryDelays); try++ {
-		resp, err = client.Do(req)
-		if err == nil {
-			debug.Webhook.Debug("fired webhook for event '%s' (uuid: %s)", webhook.Event, webhook.UUID)
-			break
-		}
-		defer resp.Body.Close() // nolint:errcheck
-
-		if try < len(retryDelays) {
-			time.Sleep(retryDelays[try])
-			continue
-		}
-
-		debug.Log.Error("failed to fire webhook for event '%s' (uuid: %s) after %d tries", webhook.Event, webhook.UUID, try+1)
-	}
+const (
+	SignatureHeader = "X-FFmate-Signature"
+	TimestampHeader = "X-FFmate-Timestamp"
+)
 
-	// Save a copy of body for the callback
-	req.Body = io.NopCloser(bytes.NewBuffer(b))
-	callback(webhook.Event, webhook.URL, req, resp)
+// Sign sets the timestamp header and the HMAC-SHA256 signature of "<timestamp>.<body>" using the secret
+func Sign(header http.Header, secret string, body []byte, timestamp time.Time) {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	header.Set(TimestampHeader, ts)
+	header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
 }
 
 func (s *Service) Name() string {
@@ -25,6 +25,13 @@ const (
 	TaskCreated WebsocketSubject = "task:created"
 	TaskUpdated WebsocketSubject = "task:updated"
 	TaskDeleted WebsocketSubject = "task:deleted"
+	TaskCancel  WebsocketSubject = "task:cancel"
+	TaskPause   WebsocketSubject = "task:pause"
+	TaskResume  WebsocketSubject = "task:resume"
+
+	TaskLogCreated WebsocketSubject = "taskLog:created"
+
+	BatchUpdated WebsocketSubject = "batch:updated"
 
 	PresetCreated WebsocketSubject = "preset:created"
 	PresetUpdated WebsocketSubject = "preset:updated"
@@ -34,6 +41,10 @@ const (
 	WatchfolderUpdated WebsocketSubject = "watchfolder:updated"
 	WatchfolderDeleted WebsocketSubject = "watchfolder:deleted"
 
+	ScheduleCreated WebsocketSubject = "schedule:created"
+	ScheduleUpdated WebsocketSubject = "schedule:updated"
+	ScheduleDeleted WebsocketSubject = "schedule:deleted"
+
 	WebhookCreated WebsocketSubject = "webhook:created"
 	WebhookUpdated WebsocketSubject = "webhook:updated"
 	WebhookDeleted WebsocketSubject = "webhook:deleted"
@@ -43,6 +54,7 @@ const (
 	SettingsUpdated WebsocketSubject = "settings:updated"
 
 	ClientUpdated WebsocketSubject = "client:updated"
+	QueueUpdate   WebsocketSubject = "queue:update"
 
 	Log WebsocketSubject = "log:created"
 )
@@ -108,6 +120,15 @@ func (s *Service) Broadcast(subject WebsocketSubject, msg any) {
 	}
 }
 
+// BroadcastLocal sends a message to the websocket clients of this node only
+func (s *Service) BroadcastLocal(subject WebsocketSubject, msg any) {
+	select {
+	case broadcastQueue <- broadcastMessage{msg, subject}:
+	default:
+		debug.Websocket.Debug("dropped local broadcast due to blocked channel (full)")
+	}
+}
+
 func (s *Service) processBroadcastQueue() {
 	for b := range broadcastQueue {
 		s.broadcastLocal(b.subject, b.msg)
@@ -137,6 +158,26 @@ var notifyQueue = make(chan *ClusterUpdate, 1000)
 var isCluster = false
 var session = ""
 
+// clusterHandlers receive cluster updates for internal subjects instead of websocket clients
+var clusterHandlers = sync.Map{}
+
+// HandleCluster registers a handler for updates of the given subject sent by other cluster members
+func (s *Service) HandleCluster(subject WebsocketSubject, handler func(payload any)) {
+	clusterHandlers.Store(subject, handler)
+}
+
+// NotifyCluster sends an update to all other cluster members without broadcasting it to websocket clients
+func (s *Service) NotifyCluster(subject WebsocketSubject, msg any) {
+	if !isCluster {
+		return
+	}
+	select {
+	case notifyQueue <- &ClusterUpdate{Subject: subject, Payload: msg, Client: session}:
+	default:
+		debug.Websocket.Debug("dropped cluster notification due to blocked channel (full)")
+	}
+}
+
 func (s *Service) InitCluster() {
 	session = cfg.GetString("ffmate.session")
 	isCluster = true
@@ -185,6 +226,11 @@ func (s *Service) listenCluster() {
 			if payload.Client != session {
 				debug.Websocket.Debug("> %s from %s (size: %db)", payload.Subject, payload.Client, len(n.Extra))
 
+				if handler, ok := clusterHandlers.Load(payload.Subject); ok {
+					go handler.(func(payload any))(payload.Payload)
+					continue
+				}
+
 				// remove self from external clients
 				if payload.Subject == string(ClientUpdated) {
 					delete(payload.Payload.(map[string]any), "self")
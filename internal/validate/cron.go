@@ -0,0 +1,28 @@
+package validate
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/cron"
+	"goyave.dev/goyave/v5/validation"
+)
+
+type cronValidator struct {
+	validation.BaseValidator
+}
+
+// Cron validates that the field under validation is a valid cron expression
+func Cron() validation.Validator {
+	return validation.WithMessage(&cronValidator{}, "The :field must be a valid cron expression.")
+}
+
+func (v *cronValidator) Name() string {
+	return "cron"
+}
+
+func (v *cronValidator) Validate(ctx *validation.Context) bool {
+	val, ok := ctx.Value.(string)
+	if !ok {
+		return false
+	}
+	_, err := cron.Parse(val)
+	return err == nil
+}
@@ -0,0 +1,18 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/validation"
+)
+
+func TestCronValidator(t *testing.T) {
+	v := Cron()
+
+	require.Equal(t, "cron", v.Name())
+	require.True(t, v.Validate(&validation.Context{Value: "0 2 * * *"}))
+	require.True(t, v.Validate(&validation.Context{Value: "@daily"}))
+	require.False(t, v.Validate(&validation.Context{Value: "0 25 * * *"}))
+	require.False(t, v.Validate(&validation.Context{Value: 123}))
+}
@@ -0,0 +1,29 @@
+package validate
+
+import (
+	"regexp"
+
+	"goyave.dev/goyave/v5/validation"
+)
+
+type regexpValidator struct {
+	validation.BaseValidator
+}
+
+// Regexp validates that the field under validation is a compilable regular expression
+func Regexp() validation.Validator {
+	return validation.WithMessage(&regexpValidator{}, "The :field must be a valid regular expression.")
+}
+
+func (v *regexpValidator) Name() string {
+	return "regexp"
+}
+
+func (v *regexpValidator) Validate(ctx *validation.Context) bool {
+	val, ok := ctx.Value.(string)
+	if !ok {
+		return false
+	}
+	_, err := regexp.Compile(val)
+	return err == nil
+}
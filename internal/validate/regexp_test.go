@@ -0,0 +1,18 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/validation"
+)
+
+func TestRegexpValidator(t *testing.T) {
+	v := Regexp()
+
+	require.Equal(t, "regexp", v.Name())
+	require.True(t, v.Validate(&validation.Context{Value: `Connection (refused|reset)`}))
+	require.True(t, v.Validate(&validation.Context{Value: ""}))
+	require.False(t, v.Validate(&validation.Context{Value: `(unclosed`}))
+	require.False(t, v.Validate(&validation.Context{Value: 123}))
+}
@@ -0,0 +1,28 @@
+package validate
+
+import (
+	"github.com/welovemedia/ffmate/v2/internal/service/webhook"
+	"goyave.dev/goyave/v5/validation"
+)
+
+type templateValidator struct {
+	validation.BaseValidator
+}
+
+// Template validates that the field under validation is a parsable webhook payload template
+func Template() validation.Validator {
+	return validation.WithMessage(&templateValidator{}, "The :field must be a valid template.")
+}
+
+func (v *templateValidator) Name() string {
+	return "template"
+}
+
+func (v *templateValidator) Validate(ctx *validation.Context) bool {
+	val, ok := ctx.Value.(string)
+	if !ok {
+		return false
+	}
+	_, err := webhook.ParseTemplate(val)
+	return err == nil
+}
@@ -0,0 +1,19 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/validation"
+)
+
+func TestTemplateValidator(t *testing.T) {
+	v := Template()
+
+	require.Equal(t, "template", v.Name())
+	require.True(t, v.Validate(&validation.Context{Value: `{"text": {{json .data.name}}}`}))
+	require.True(t, v.Validate(&validation.Context{Value: ""}))
+	require.False(t, v.Validate(&validation.Context{Value: `{{.data.name`}))
+	require.False(t, v.Validate(&validation.Context{Value: `{{unknown .data}}`}))
+	require.False(t, v.Validate(&validation.Context{Value: 123}))
+}
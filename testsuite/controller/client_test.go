@@ -1,14 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/welovemedia/ffmate/v2/internal/cfg"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/client"
 	"github.com/welovemedia/ffmate/v2/testsuite"
+	"gorm.io/gorm"
 )
 
 func TestClient(t *testing.T) {
@@ -24,3 +29,77 @@ func TestClient(t *testing.T) {
 	assert.Equal(t, body[0].Session, cfg.GetString("ffmate.session"), "GET /api/v1/clients")
 	assert.True(t, body[0].Self, "GET /api/v1/clients")
 }
+
+func TestClientQueue(t *testing.T) {
+	server := testsuite.InitServer(t)
+	defer cfg.Set("ffmate.queue", dto.QueueActive)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/v1/clients/queue", strings.NewReader(`{"status":"sleeping"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "PATCH /api/v1/clients/queue")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/clients/unknown/queue", strings.NewReader(`{"status":"paused"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "PATCH /api/v1/clients/{identifier}/queue")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/clients/"+cfg.GetString("ffmate.identifier")+"/queue", strings.NewReader(`{"status":"draining"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	queue, _ := testsuite.ParseJSONBody[dto.Queue](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/clients/{identifier}/queue")
+	assert.Equal(t, dto.QueueDraining, queue.Status, "PATCH /api/v1/clients/{identifier}/queue")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/clients", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	clients, _ := testsuite.ParseJSONBody[[]dto.Client](response.Body)
+	assert.Equal(t, dto.QueueDrained, clients[0].Queue, "GET /api/v1/clients")
+
+	request = httptest.NewRequest(http.MethodGet, "/health", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	health, _ := testsuite.ParseJSONBody[dto.Health](response.Body)
+	assert.Equal(t, dto.QueueDrained, health.Queue, "GET /health")
+	assert.Equal(t, 0, health.RunningTasks, "GET /health")
+	assert.True(t, health.Drained, "GET /health")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/clients/queue", strings.NewReader(`{"status":"active"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/clients/queue")
+	assert.Equal(t, dto.QueueActive, client.QueueStatus(), "PATCH /api/v1/clients/queue")
+
+	// the queue status is restored after a restart
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/clients/queue", strings.NewReader(`{"status":"paused"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	cfg.Set("ffmate.queue", dto.QueueActive)
+	server.Service(service.Client).(*client.Service).UpdateClientInfo()
+	assert.Equal(t, dto.QueuePaused, client.QueueStatus(), "restart")
+}
+
+func TestClientQueueSaveFailure(t *testing.T) {
+	server := testsuite.InitServer(t)
+	defer cfg.Set("ffmate.queue", dto.QueueActive)
+
+	fail := func(db *gorm.DB) {
+		if db.Statement.Schema != nil && db.Statement.Schema.Name == "Client" {
+			_ = db.AddError(errors.New("database unavailable"))
+		}
+	}
+	_ = server.DB().Callback().Create().Before("gorm:create").Register("test:fail_client", fail)
+	_ = server.DB().Callback().Update().Before("gorm:update").Register("test:fail_client", fail)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/v1/clients/queue", strings.NewReader(`{"status":"paused"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "PATCH /api/v1/clients/queue")
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/testsuite"
+
+	_ "goyave.dev/goyave/v5/database/dialect/sqlite"
+)
+
+func TestJanitorDryRunAndRun(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	server.DB().Create(&model.Task{UUID: uuid.NewString(), Status: dto.DoneError, FinishedAt: time.Now().Add(-72 * time.Hour).UnixMilli()})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/settings", strings.NewReader(`{"retention":{"doneError":2,"deleted":1,"webhookExecutions":100}}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/settings")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/janitor/dry-run", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ := testsuite.ParseJSONBody[dto.JanitorReport](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/janitor/dry-run")
+	assert.True(t, body.DryRun, "GET /api/v1/janitor/dry-run")
+	assert.Equal(t, int64(1), body.Tasks, "GET /api/v1/janitor/dry-run")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/janitor/run", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ = testsuite.ParseJSONBody[dto.JanitorReport](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/janitor/run")
+	assert.False(t, body.DryRun, "POST /api/v1/janitor/run")
+	assert.Equal(t, int64(1), body.Tasks, "POST /api/v1/janitor/run")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/janitor/dry-run", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ = testsuite.ParseJSONBody[dto.JanitorReport](response.Body)
+	assert.Equal(t, int64(0), body.Tasks, "GET /api/v1/janitor/dry-run")
+}
@@ -2,10 +2,13 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -144,3 +147,125 @@ func TestPresetUpdate(t *testing.T) {
 	preset, _ = testsuite.ParseJSONBody[dto.Preset](response.Body)
 	assert.Equal(t, 400, response.StatusCode, "GET /api/v1/presets/{uuid}")
 }
+
+func TestPresetRules(t *testing.T) {
+	server := testsuite.InitServer(t)
+	fakeFFprobe(t)
+
+	addPreset := func(body string) (int, dto.Preset) {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/presets", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		if response.StatusCode != http.StatusOK {
+			return response.StatusCode, dto.Preset{}
+		}
+		preset, _ := testsuite.ParseJSONBody[dto.Preset](response.Body)
+		return response.StatusCode, preset
+	}
+
+	_, uhd := addPreset(`{"name":"uhd","command":"-uhd"}`)
+	_, audio := addPreset(`{"name":"audio","command":"-audio"}`)
+	_, mxf := addPreset(`{"name":"mxf","command":"-mxf"}`)
+	_, customer := addPreset(`{"name":"customer","command":"-customer"}`)
+
+	status, router := addPreset(`{"name":"router","command":"-default","rules":[
+		{"preset":"` + uhd.UUID + `","conditions":[{"field":"probe.video.height","operator":"gte","value":2160}]},
+		{"preset":"` + audio.UUID + `","conditions":[{"field":"probe.video","operator":"notExists"},{"field":"probe.audio","operator":"exists"}]},
+		{"preset":"` + mxf.UUID + `","conditions":[{"field":"file.extension","operator":"eq","value":"MXF"}]},
+		{"preset":"` + customer.UUID + `","conditions":[{"field":"metadata.customer","operator":"matches","value":"^acme"}]}
+	]}`)
+	assert.Equal(t, http.StatusOK, status, "POST /api/v1/presets")
+	assert.Len(t, *router.Rules, 4, "POST /api/v1/presets")
+
+	for input, expected := range map[string]dto.Preset{
+		"uhd.mov":     uhd,
+		"sound.wav":   audio,
+		"clip.mxf":    mxf,
+		"clip.mp4":    router,
+		"acme.mp4":    customer,
+		"":            router,
+		"missing.mp4": router,
+	} {
+		metadata := ""
+		if input == "acme.mp4" {
+			metadata = `,"metadata":{"customer":"acme-corp"}`
+		}
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/resolve", strings.NewReader(`{"name":"rules","preset":"`+router.UUID+`","inputFile":"`+input+`"`+metadata+`}`))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		body, _ := testsuite.ParseJSONBody[dto.ResolvedTask](response.Body)
+
+		assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks/resolve")
+		assert.Equal(t, expected.UUID, body.Preset, input)
+		assert.Equal(t, expected.Command, body.Command, input)
+	}
+
+	// referenced presets must exist
+	status, _ = addPreset(`{"name":"invalid","command":"-y","rules":[{"preset":"unknown","conditions":[]}]}`)
+	assert.Equal(t, http.StatusBadRequest, status, "POST /api/v1/presets")
+
+	// the command is optional for presets with rules but required without
+	status, _ = addPreset(`{"name":"invalid","rules":[{"preset":"` + uhd.UUID + `","conditions":[{"field":"file.extension","operator":"like","value":"mp4"}]}]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status, "POST /api/v1/presets")
+	status, _ = addPreset(`{"name":"invalid"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status, "POST /api/v1/presets")
+
+	// without a matching rule and command the task can not be created
+	_, strict := addPreset(`{"name":"strict","rules":[{"preset":"` + uhd.UUID + `","conditions":[{"field":"probe.video.height","operator":"gte","value":2160}]}]}`)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"name":"rules","preset":"`+strict.UUID+`","inputFile":"clip.mp4"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/tasks")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"name":"rules","preset":"`+strict.UUID+`","inputFile":"uhd.mov"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks")
+	assert.Equal(t, uhd.UUID, task.Preset, "POST /api/v1/tasks")
+	assert.Equal(t, 2160, task.Probe.Video.Height, "POST /api/v1/tasks")
+
+	// rules must not select presets without command
+	status, _ = addPreset(`{"name":"invalid","command":"-y","rules":[{"preset":"` + strict.UUID + `","conditions":[]}]}`)
+	assert.Equal(t, http.StatusBadRequest, status, "POST /api/v1/presets")
+
+	// match expressions must compile
+	status, _ = addPreset(`{"name":"invalid","command":"-y","rules":[{"preset":"` + uhd.UUID + `","conditions":[{"field":"file.name","operator":"matches","value":"(acme"}]}]}`)
+	assert.Equal(t, http.StatusBadRequest, status, "POST /api/v1/presets")
+}
+
+func TestPresetRulesRequestCanceled(t *testing.T) {
+	server := testsuite.InitServer(t)
+	fakeFFprobe(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/presets", strings.NewReader(`{"name":"uhd","command":"-uhd"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	uhd, _ := testsuite.ParseJSONBody[dto.Preset](response.Body)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/presets", strings.NewReader(`{"name":"router","command":"-default","rules":[
+		{"preset":"`+uhd.UUID+`","conditions":[{"field":"probe.video.height","operator":"gte","value":2160}]}
+	]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	router, _ := testsuite.ParseJSONBody[dto.Preset](response.Body)
+
+	// probing the input file for the rules ends with the request
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	request = httptest.NewRequestWithContext(ctx, http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"name":"slow","preset":"`+router.UUID+`","inputFile":"slow.mp4"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Less(t, time.Since(start), 5*time.Second, "POST /api/v1/tasks")
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks")
+	assert.Equal(t, router.UUID, task.Preset, "POST /api/v1/tasks")
+}
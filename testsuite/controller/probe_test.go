@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/testsuite"
+
+	_ "goyave.dev/goyave/v5/database/dialect/sqlite"
+)
+
+// fakeFFprobe installs a fake ffprobe printing a 1280x720 h264 stream (3840x2160 for "uhd*", audio only for "*.wav"), failing for "missing.mp4"
+func fakeFFprobe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+	bin := filepath.Join(t.TempDir(), "ffprobe")
+	script := `#!/bin/sh
+for last; do :; done
+if [ "$last" = "missing.mp4" ]; then echo "missing.mp4: No such file or directory" >&2; exit 1; fi
+case "$last" in
+  slow*) exec sleep 10;;
+  uhd*) echo '{"streams":[{"index":0,"codec_type":"video","codec_name":"hevc","width":3840,"height":2160}],"format":{"filename":"'"$last"'"}}'; exit 0;;
+  *.wav) echo '{"streams":[{"index":0,"codec_type":"audio","codec_name":"pcm_s16le","channels":2}],"format":{"filename":"'"$last"'"}}'; exit 0;;
+esac
+echo '{"streams":[{"index":0,"codec_type":"video","codec_name":"h264","width":1280,"height":720,"r_frame_rate":"25/1"}],"format":{"filename":"'"$last"'","format_name":"mp4","duration":"10.0"}}'
+`
+	assert.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	cfg.Set("ffmate.ffprobe", bin)
+	t.Cleanup(func() { cfg.Set("ffmate.ffprobe", "") })
+}
+
+func TestProbe(t *testing.T) {
+	server := testsuite.InitServer(t)
+	fakeFFprobe(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/probe", strings.NewReader(`{"inputFile":"input.mp4"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ := testsuite.ParseJSONBody[dto.Probe](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/probe")
+	assert.Equal(t, "input.mp4", body.Format.Filename, "POST /api/v1/probe")
+	assert.Equal(t, 1280, body.Video.Width, "POST /api/v1/probe")
+	assert.Equal(t, float64(25), body.Video.FrameRate, "POST /api/v1/probe")
+	assert.Nil(t, body.Audio, "POST /api/v1/probe")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/probe", strings.NewReader(`{"inputFile":"missing.mp4"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/probe")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/probe", strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/probe")
+}
+
+func TestProbeWildcards(t *testing.T) {
+	server := testsuite.InitServer(t)
+	fakeFFprobe(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/resolve", strings.NewReader(`{"name":"probe","inputFile":"input.mp4","outputFile":"output.mp4","command":"-i ${INPUT_FILE} -s ${PROBE_video.width}x${PROBE_video.height} -c:v ${PROBE_streams.0.codecName} ${PROBE_audio.codecName}${PROBE_unknown} ${OUTPUT_FILE}"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ := testsuite.ParseJSONBody[dto.ResolvedTask](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, `-i "input.mp4" -s 1280x720 -c:v h264  "output.mp4"`, body.Command, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, "h264", body.Probe.Video.CodecName, "POST /api/v1/tasks/resolve")
+	assert.ElementsMatch(t, []string{"${PROBE_audio.codecName}", "${PROBE_unknown}"}, body.UnknownWildcards, "POST /api/v1/tasks/resolve")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks/resolve", strings.NewReader(`{"name":"probe","inputFile":"missing.mp4","command":"-i ${INPUT_FILE} ${PROBE_video.width}"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ = testsuite.ParseJSONBody[dto.ResolvedTask](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks/resolve")
+	assert.Nil(t, body.Probe, "POST /api/v1/tasks/resolve")
+	assert.Contains(t, body.ProbeError, "No such file", "POST /api/v1/tasks/resolve")
+}
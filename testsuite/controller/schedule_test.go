@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/testsuite"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+var newSchedule = &dto.NewSchedule{
+	Name:        "Test schedule",
+	Description: "Test desc",
+
+	Cron:   "0 2 * * *",
+	Preset: "123",
+
+	InputFile:  "/archive",
+	OutputFile: "/archive/out.mp4",
+}
+
+func createSchedule(t *testing.T, server *testutil.TestServer) *http.Response {
+	body, _ := json.Marshal(newSchedule)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/schedules")
+	return response
+}
+
+func TestScheduleCreate(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createSchedule(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	schedule, _ := testsuite.ParseJSONBody[dto.Schedule](response.Body)
+
+	assert.Equal(t, "Test schedule", schedule.Name, "POST /api/v1/schedules")
+	assert.NotEmpty(t, schedule.UUID, "POST /api/v1/schedules")
+	assert.NotZero(t, schedule.NextRun, "POST /api/v1/schedules")
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", strings.NewReader(`{"name":"invalid","preset":"123","cron":"0 25 * * *"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/schedules")
+}
+
+func TestScheduleList(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createSchedule(t, server)
+	defer response.Body.Close() // nolint:errcheck
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/schedules", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+
+	schedules, _ := testsuite.ParseJSONBody[[]dto.Schedule](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/schedules")
+	assert.Len(t, schedules, 1, "GET /api/v1/schedules")
+	assert.Equal(t, "1", response.Header.Get("X-Total"), "GET /api/v1/schedules")
+}
+
+func TestScheduleUpdate(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createSchedule(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	schedule, _ := testsuite.ParseJSONBody[dto.Schedule](response.Body)
+
+	request := httptest.NewRequest(http.MethodPut, "/api/v1/schedules/"+schedule.UUID, strings.NewReader(`{"name":"Nightly","preset":"123","cron":"@hourly","suspended":true}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	updated, _ := testsuite.ParseJSONBody[dto.Schedule](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PUT /api/v1/schedules/{uuid}")
+	assert.Equal(t, "Nightly", updated.Name, "PUT /api/v1/schedules/{uuid}")
+	assert.Equal(t, "@hourly", updated.Cron, "PUT /api/v1/schedules/{uuid}")
+	assert.True(t, updated.Suspended, "PUT /api/v1/schedules/{uuid}")
+}
+
+func TestScheduleDelete(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createSchedule(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	schedule, _ := testsuite.ParseJSONBody[dto.Schedule](response.Body)
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/v1/schedules/"+schedule.UUID, nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, 204, response.StatusCode, "DELETE /api/v1/schedules")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/schedules/"+uuid.NewString(), nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, 400, response.StatusCode, "GET /api/v1/schedules/{uuid}")
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service/settings"
 	"github.com/welovemedia/ffmate/v2/testsuite"
 
 	_ "goyave.dev/goyave/v5/database/dialect/sqlite"
@@ -35,3 +38,64 @@ func TestSettingsStore(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/settings")
 }
+
+func TestSettingsStoreApplied(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/settings", strings.NewReader(`{"maxConcurrentTasks":8,"defaultPriority":5,"retention":{"doneSuccessful":7},"nodes":[{"identifier":"`+cfg.GetString("ffmate.identifier")+`","maxConcurrentTasks":2}]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ := testsuite.ParseJSONBody[dto.Settings](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/settings")
+	assert.Equal(t, uint(8), *body.MaxConcurrentTasks, "POST /api/v1/settings")
+	assert.Len(t, body.Nodes, 1, "POST /api/v1/settings")
+	assert.Equal(t, 2, settings.MaxConcurrentTasks(), "POST /api/v1/settings")
+	assert.Equal(t, uint(5), settings.DefaultPriority(), "POST /api/v1/settings")
+	assert.Equal(t, uint(7), settings.Retention().DoneSuccessful, "POST /api/v1/settings")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/settings", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ = testsuite.ParseJSONBody[dto.Settings](response.Body)
+	assert.Equal(t, uint(5), *body.DefaultPriority, "GET /api/v1/settings")
+
+	// the default priority applies to tasks without a priority only, an explicit 0 is kept
+	for body, expected := range map[string]uint{
+		`{"name":"default","command":"-y"}`:               5,
+		`{"name":"explicit","command":"-y","priority":0}`: 0,
+	} {
+		request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response = server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+		assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks")
+		assert.Equal(t, expected, task.Priority, "POST /api/v1/tasks")
+	}
+
+	// a node without slots would stop processing, neither can a retention period be unbounded
+	for _, body := range []string{
+		`{"maxConcurrentTasks":-1}`,
+		`{"maxConcurrentTasks":0}`,
+		`{"nodes":[{"identifier":"node","maxConcurrentTasks":0}]}`,
+		`{"retention":{"doneSuccessful":36501}}`,
+		`{"retention":{"deleted":18446744073709551615}}`,
+	} {
+		request = httptest.NewRequest(http.MethodPost, "/api/v1/settings", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response = server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/settings")
+	}
+
+	// unset settings fall back to the flags
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/settings", strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/settings")
+	assert.Equal(t, cfg.GetInt("ffmate.maxConcurrentTasks"), settings.MaxConcurrentTasks(), "POST /api/v1/settings")
+	assert.Nil(t, settings.Retention(), "POST /api/v1/settings")
+}
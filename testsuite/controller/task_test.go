@@ -5,23 +5,30 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/database/repository"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/service"
 	"github.com/welovemedia/ffmate/v2/internal/service/client"
+	taskSvc "github.com/welovemedia/ffmate/v2/internal/service/task"
 	"github.com/welovemedia/ffmate/v2/testsuite"
 
 	_ "goyave.dev/goyave/v5/database/dialect/sqlite"
 	"goyave.dev/goyave/v5/util/testutil"
+	"goyave.dev/goyave/v5/util/typeutil"
 )
 
 var newTask = &dto.NewTask{
 	Name:       "Test task",
 	Command:    "-y",
-	Priority:   100,
+	Priority:   typeutil.NewUndefined[uint](100),
 	OutputFile: "/dev/null",
 	PreProcessing: &dto.NewPrePostProcessing{
 		SidecarPath:   "/dev/null",
@@ -74,6 +81,18 @@ func TestTaskCreate(t *testing.T) {
 	assert.Equal(t, "/dev/null", task.PostProcessing.ScriptPath.Raw, "POST /api/v1/tasks")
 }
 
+func TestTaskCreateRetryBounds(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	for _, retry := range []string{`{"maxAttempts":101}`, `{"maxAttempts":3,"delay":86401}`} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"name":"retry","command":"-y","retry":`+retry+`}`))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/tasks")
+	}
+}
+
 func TestTaskList(t *testing.T) {
 	server := testsuite.InitServer(t)
 
@@ -189,3 +208,556 @@ func TestTaskCreateBatch(t *testing.T) {
 	assert.Equal(t, body2.Tasks[0].Batch, body.UUID, "GET /api/v1/batches/{uuid}")
 	assert.Equal(t, body2.UUID, body.UUID, "GET /api/v1/batches/{uuid}")
 }
+
+func TestTaskLogs(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createTask(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+
+	repo := (&repository.TaskLog{DB: server.DB()}).Setup()
+	_ = repo.Add([]*model.TaskLog{
+		{Task: task.UUID, Source: dto.TaskLogFFmpeg, Line: "line 1"},
+		{Task: task.UUID, Source: dto.TaskLogFFmpeg, Line: "line 2"},
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+task.UUID+"/logs", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	logs, _ := testsuite.ParseJSONBody[[]dto.TaskLog](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/tasks/{uuid}/logs")
+	assert.Equal(t, "2", response.Header.Get("X-Total"), "GET /api/v1/tasks/{uuid}/logs")
+	assert.Len(t, logs, 2, "GET /api/v1/tasks/{uuid}/logs")
+	assert.Equal(t, "line 1", logs[0].Line, "GET /api/v1/tasks/{uuid}/logs")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+uuid.NewString()+"/logs", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "GET /api/v1/tasks/{uuid}/logs")
+}
+
+func TestTaskCreateWithRetry(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	body, _ := json.Marshal(&dto.NewTask{
+		Name:    "Test task",
+		Command: "-y",
+		Retry:   &dto.RetryPolicy{MaxAttempts: 3, Delay: 5, Backoff: 2, Match: "Connection (refused|reset)"},
+	})
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks")
+	assert.Equal(t, uint(3), task.Retry.MaxAttempts, "POST /api/v1/tasks")
+	assert.Equal(t, uint(0), task.Attempt, "POST /api/v1/tasks")
+
+	body, _ = json.Marshal(&dto.NewTask{
+		Name:    "Test task",
+		Command: "-y",
+		Retry:   &dto.RetryPolicy{MaxAttempts: 3, Match: "(unclosed"},
+	})
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/tasks")
+}
+
+func TestTaskCreateWithPresetConstraints(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	body, _ := json.Marshal(&dto.NewPreset{Name: "GPU preset", Command: "-y", Labels: []string{"gpu=nvenc"}, Tags: []string{"hevc4k"}, Weight: 4, MaxConcurrent: 1})
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/presets", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	preset, _ := testsuite.ParseJSONBody[dto.Preset](response.Body)
+	assert.Equal(t, []string{"gpu=nvenc"}, preset.Labels, "POST /api/v1/presets")
+
+	body, _ = json.Marshal(&dto.NewTask{Name: "Test task", Preset: preset.UUID, Labels: []string{"site=berlin", "gpu=nvenc"}})
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks")
+	assert.ElementsMatch(t, []string{"gpu=nvenc", "site=berlin"}, task.Labels, "POST /api/v1/tasks")
+	assert.Equal(t, []string{"hevc4k"}, task.Tags, "POST /api/v1/tasks")
+	assert.Equal(t, uint(4), task.Weight, "POST /api/v1/tasks")
+	assert.Equal(t, preset.UUID, task.Preset, "POST /api/v1/tasks")
+}
+
+func TestTaskCreateWorkflow(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	// create workflow (submitted out of order)
+	workflow := dto.NewWorkflow{
+		Tasks: []*dto.NewWorkflowTask{
+			{ID: "proxy", NewTask: &dto.NewTask{Name: "Proxy", Command: "-y", DependsOn: []string{"master"}}},
+			{ID: "master", NewTask: &dto.NewTask{Name: "Master", Command: "-y"}},
+			{ID: "thumbnail", NewTask: &dto.NewTask{Name: "Thumbnail", Command: "-y", DependsOn: []string{"master"}}},
+		},
+	}
+	b, _ := json.Marshal(workflow)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/workflows", bytes.NewReader(b))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ := testsuite.ParseJSONBody[dto.Batch](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/workflows")
+	assert.Len(t, body.Tasks, 3, "POST /api/v1/workflows")
+	assert.Equal(t, "Master", body.Tasks[0].Name, "POST /api/v1/workflows")
+	assert.Empty(t, body.Tasks[0].DependsOn, "POST /api/v1/workflows")
+	assert.Equal(t, []string{body.Tasks[0].UUID}, body.Tasks[1].DependsOn, "POST /api/v1/workflows")
+	assert.Equal(t, []string{body.Tasks[0].UUID}, body.Tasks[2].DependsOn, "POST /api/v1/workflows")
+
+	// cancel master, dependents must be canceled as well
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+body.Tasks[0].UUID+"/cancel", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/cancel")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+body.Tasks[1].UUID, nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, dto.DoneCanceled, task.Status, "GET /api/v1/tasks/{uuid}")
+	assert.Contains(t, task.Error, body.Tasks[0].UUID, "GET /api/v1/tasks/{uuid}")
+
+	// depending on a canceled task is rejected
+	b, _ = json.Marshal(&dto.NewTask{Name: "Late", Command: "-y", DependsOn: []string{body.Tasks[0].UUID}})
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewReader(b))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/tasks")
+
+	// each task of the workflow uses its own preset
+	var presets []*dto.Preset
+	for _, command := range []string{"-i ${INPUT_FILE} -c:v prores ${OUTPUT_FILE}", "-i ${INPUT_FILE} -vf scale=640:-2 ${OUTPUT_FILE}"} {
+		b, _ = json.Marshal(&dto.NewPreset{Name: "Workflow preset", Command: command, OutputFile: "/dev/null"})
+		request = httptest.NewRequest(http.MethodPost, "/api/v1/presets", bytes.NewReader(b))
+		request.Header.Set("Content-Type", "application/json")
+		response = server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		preset, _ := testsuite.ParseJSONBody[dto.Preset](response.Body)
+		assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/presets")
+		presets = append(presets, &preset)
+	}
+
+	workflow = dto.NewWorkflow{
+		Tasks: []*dto.NewWorkflowTask{
+			{ID: "master", NewTask: &dto.NewTask{Name: "Master", Preset: presets[0].UUID, InputFile: "/tmp/in.mov"}},
+			{ID: "proxy", NewTask: &dto.NewTask{Name: "Proxy", Preset: presets[1].UUID, InputFile: "/tmp/in.mov", DependsOn: []string{"master"}}},
+		},
+	}
+	b, _ = json.Marshal(workflow)
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/workflows", bytes.NewReader(b))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ = testsuite.ParseJSONBody[dto.Batch](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/workflows")
+	assert.Len(t, body.Tasks, 2, "POST /api/v1/workflows")
+	assert.Equal(t, presets[0].UUID, body.Tasks[0].Preset, "POST /api/v1/workflows")
+	assert.Equal(t, presets[0].Command, body.Tasks[0].Command.Raw, "POST /api/v1/workflows")
+	assert.Equal(t, presets[1].UUID, body.Tasks[1].Preset, "POST /api/v1/workflows")
+	assert.Equal(t, presets[1].Command, body.Tasks[1].Command.Raw, "POST /api/v1/workflows")
+}
+
+func TestTaskCreateWorkflowInvalid(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	// cycle
+	workflow := dto.NewWorkflow{
+		Tasks: []*dto.NewWorkflowTask{
+			{ID: "a", NewTask: &dto.NewTask{Name: "A", Command: "-y", DependsOn: []string{"b"}}},
+			{ID: "b", NewTask: &dto.NewTask{Name: "B", Command: "-y", DependsOn: []string{"a"}}},
+		},
+	}
+	b, _ := json.Marshal(workflow)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/workflows", bytes.NewReader(b))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/workflows")
+
+	// a failing task must not leave the tasks before it queued
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/workflows", bytes.NewReader([]byte(`{"tasks":[
+		{"id":"master","name":"Master","command":"-y"},
+		{"id":"proxy","name":"Proxy","command":"-y","dependsOn":["master"]},
+		{"id":"hls","name":"HLS","preset":"unknown","dependsOn":["proxy"]}
+	]}`)))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/workflows")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, "0", response.Header.Get("X-Total"), "GET /api/v1/tasks")
+
+	// neither command nor preset
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/workflows", bytes.NewReader([]byte(`{"tasks":[{"id":"a","name":"A"}]}`)))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/workflows")
+}
+
+func TestTaskCancel(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createTask(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/cancel?cleanup=maybe", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/cancel")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/cancel?cleanup=true", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ = testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/cancel")
+	assert.Equal(t, dto.DoneCanceled, task.Status, "PATCH /api/v1/tasks/{uuid}/cancel")
+}
+
+func TestTaskPauseResume(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createTask(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+
+	// resuming a task that is not paused fails
+	request := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/resume", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/resume")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/pause", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ = testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/pause")
+	assert.Equal(t, dto.Paused, task.Status, "PATCH /api/v1/tasks/{uuid}/pause")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/resume", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ = testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/resume")
+	assert.Equal(t, dto.Queued, task.Status, "PATCH /api/v1/tasks/{uuid}/resume")
+
+	// finished tasks can not be paused
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/cancel", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/pause", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/pause")
+}
+
+func TestTaskPauseRemote(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	response := createTask(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+
+	// the task is processed by another node
+	server.DB().Model(&model.Task{}).Where("uuid = ?", task.UUID).Updates(map[string]any{"status": dto.Running, "started_at": time.Now().UnixMilli(), "client_identifier": "other"})
+
+	// outside of a cluster there is no node to signal
+	request := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/pause", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/pause")
+
+	// the status is kept until the owning node confirms the pause
+	cfg.Set("ffmate.isCluster", true)
+	defer cfg.Set("ffmate.isCluster", false)
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+task.UUID+"/pause", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ = testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/tasks/{uuid}/pause")
+	assert.Equal(t, dto.Running, task.Status, "PATCH /api/v1/tasks/{uuid}/pause")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+task.UUID, nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	task, _ = testsuite.ParseJSONBody[dto.Task](response.Body)
+	assert.Equal(t, dto.Running, task.Status, "GET /api/v1/tasks/{uuid}")
+}
+
+func TestTaskListFilter(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	server.DB().Create([]*model.Task{
+		{UUID: uuid.NewString(), Name: "Intro Render", Status: dto.DoneSuccessful, Source: dto.API, Priority: 1, CreatedAt: 1000, FinishedAt: 2000, Metadata: &dto.MetadataMap{"project": map[string]any{"id": "42"}}},
+		{UUID: uuid.NewString(), Name: "outro", Status: dto.DoneError, Source: dto.WATCHFOLDER, Priority: 3, CreatedAt: 3000, FinishedAt: 4000, InputFile: &dto.RawResolved{Raw: "/media/intro.mov", Resolved: "/mnt/media/intro.mov"}},
+		{UUID: uuid.NewString(), Name: "credits", Status: dto.DoneCanceled, Source: dto.SCHEDULE, Priority: 2, CreatedAt: 5000, Batch: "b1"},
+	})
+
+	list := func(query string) ([]dto.Task, string) {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?"+query, nil)
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/tasks?"+query)
+		tasks, _ := testsuite.ParseJSONBody[[]dto.Task](response.Body)
+		return tasks, response.Header.Get("X-Total")
+	}
+
+	tasks, total := list("status=DONE_SUCCESSFUL&status=DONE_ERROR")
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, "2", total)
+
+	tasks, _ = list("source=schedule")
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "credits", tasks[0].Name)
+
+	tasks, _ = list("search=INTRO&sort=createdAt&order=asc")
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, "Intro Render", tasks[0].Name)
+
+	// only the raw input path is searched, not the serialized input file
+	for _, search := range []string{"raw", "resolved", "res", "%22", "mnt"} {
+		tasks, _ = list("search=" + search)
+		assert.Empty(t, tasks, "GET /api/v1/tasks?search="+search)
+	}
+
+	tasks, _ = list("metadata=project.id:42")
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "Intro Render", tasks[0].Name)
+
+	tasks, _ = list("batch=b1")
+	assert.Len(t, tasks, 1)
+
+	tasks, _ = list("createdFrom=2000&createdTo=4000")
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "outro", tasks[0].Name)
+
+	tasks, _ = list("finishedTo=3000")
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "Intro Render", tasks[0].Name)
+
+	tasks, _ = list("sort=priority")
+	assert.Equal(t, []string{"outro", "credits", "Intro Render"}, []string{tasks[0].Name, tasks[1].Name, tasks[2].Name})
+
+	for _, query := range []string{"status=UNKNOWN", "sort=command", "order=up", "metadata=novalue", "createdFrom=-1"} {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?"+query, nil)
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "GET /api/v1/tasks?"+query)
+	}
+}
+
+func TestTaskBulk(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	failed := []*model.Task{
+		{UUID: uuid.NewString(), Name: "a", Status: dto.DoneError, Batch: "b1"},
+		{UUID: uuid.NewString(), Name: "b", Status: dto.DoneError, Batch: "b1"},
+		{UUID: uuid.NewString(), Name: "c", Status: dto.DoneSuccessful, Batch: "b1"},
+		{UUID: uuid.NewString(), Name: "d", Status: dto.DoneCanceled, Metadata: &dto.MetadataMap{"ffmate": map[string]any{"watchfolder": map[string]any{"uuid": "w1"}}}},
+	}
+	server.DB().Create(failed)
+
+	bulk := func(body string) (*dto.BulkOperation, int) {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/bulk", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		result, _ := testsuite.ParseJSONBody[dto.BulkOperation](response.Body)
+		return &result, response.StatusCode
+	}
+
+	// priority can only be changed for queued tasks
+	result, status := bulk(`{"action":"priority","priority":5,"uuids":["` + failed[0].UUID + `","unknown"]}`)
+	assert.Equal(t, http.StatusOK, status, "POST /api/v1/tasks/bulk")
+	assert.Empty(t, result.Succeeded, "POST /api/v1/tasks/bulk")
+	assert.Len(t, result.Failed, 2, "POST /api/v1/tasks/bulk")
+
+	result, status = bulk(`{"action":"delete","filter":{"batch":"b1","status":["DONE_ERROR"]}}`)
+	assert.Equal(t, http.StatusOK, status, "POST /api/v1/tasks/bulk")
+	assert.ElementsMatch(t, []string{failed[0].UUID, failed[1].UUID}, result.Succeeded, "POST /api/v1/tasks/bulk")
+
+	result, status = bulk(`{"action":"delete","filter":{"watchfolder":"w1"}}`)
+	assert.Equal(t, http.StatusOK, status, "POST /api/v1/tasks/bulk")
+	assert.Equal(t, []string{failed[3].UUID}, result.Succeeded, "POST /api/v1/tasks/bulk")
+
+	var count int64
+	server.DB().Model(&model.Task{}).Count(&count)
+	assert.Equal(t, int64(1), count, "POST /api/v1/tasks/bulk")
+
+	_, status = bulk(`{"action":"delete"}`)
+	assert.Equal(t, http.StatusBadRequest, status, "POST /api/v1/tasks/bulk")
+
+	// a filter without criteria does not select all tasks
+	_, status = bulk(`{"action":"delete","filter":{}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status, "POST /api/v1/tasks/bulk")
+	_, status = bulk(`{"action":"delete","filter":{"sort":"name"},"uuids":[]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status, "POST /api/v1/tasks/bulk")
+	_, err := server.Service(service.Task).(*taskSvc.Service).Bulk(&dto.NewBulkOperation{Action: dto.BulkDelete, Filter: &dto.TaskFilter{}})
+	assert.Error(t, err, "Bulk")
+	server.DB().Model(&model.Task{}).Count(&count)
+	assert.Equal(t, int64(1), count, "POST /api/v1/tasks/bulk")
+
+	for _, body := range []string{`{"action":"explode","uuids":["x"]}`, `{"action":"priority","uuids":["x"]}`, `{"action":"cancel","filter":{"status":["NOPE"]}}`,
+		`{"action":"delete","filter":{"metadata":["novalue"]}}`, `{"action":"delete","filter":{"metadata":"project.id:42"}}`,
+		`{"action":"delete","filter":{"search":42}}`, `{"action":"cancel","filter":{"createdFrom":-1}}`, `{"action":"cancel","filter":{"finishedTo":"yesterday"}}`} {
+		_, status = bulk(body)
+		assert.Equal(t, http.StatusUnprocessableEntity, status, "POST /api/v1/tasks/bulk")
+	}
+}
+
+func TestBatchSummaryAndOperations(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	now := time.Now().UnixMilli()
+	server.DB().Create([]*model.Task{
+		{UUID: uuid.NewString(), Name: "a", Batch: "b1", Status: dto.DoneSuccessful, Progress: 100, CreatedAt: 1000, StartedAt: now - 10000, FinishedAt: now - 5000},
+		{UUID: uuid.NewString(), Name: "b", Batch: "b1", Status: dto.Paused, Progress: 0, CreatedAt: 1001},
+		{UUID: uuid.NewString(), Name: "c", Batch: "b2", Status: dto.DoneError, Progress: 20, CreatedAt: 2000, StartedAt: now - 1000, FinishedAt: now},
+	})
+
+	// list batches
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/batches", nil)
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	batches, _ := testsuite.ParseJSONBody[[]dto.Batch](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/batches")
+	assert.Equal(t, "2", response.Header.Get("X-Total"), "GET /api/v1/batches")
+	assert.Equal(t, "b2", batches[0].UUID, "GET /api/v1/batches")
+	assert.Equal(t, dto.DoneError, batches[0].Summary.Status, "GET /api/v1/batches")
+	assert.Equal(t, float64(100), batches[0].Summary.Progress, "GET /api/v1/batches")
+
+	// get batch summary
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/batches/b1", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	batch, _ := testsuite.ParseJSONBody[dto.Batch](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/batches/{uuid}")
+	assert.Equal(t, int64(2), batch.Summary.Total, "GET /api/v1/batches/{uuid}")
+	assert.Equal(t, int64(1), batch.Summary.Counts[dto.Paused], "GET /api/v1/batches/{uuid}")
+	assert.Equal(t, dto.Paused, batch.Summary.Status, "GET /api/v1/batches/{uuid}")
+	assert.Equal(t, float64(50), batch.Summary.Progress, "GET /api/v1/batches/{uuid}")
+	assert.Equal(t, now-10000, batch.Summary.StartedAt, "GET /api/v1/batches/{uuid}")
+	assert.Zero(t, batch.Summary.FinishedAt, "GET /api/v1/batches/{uuid}")
+	assert.Greater(t, batch.Summary.Remaining, float64(0), "GET /api/v1/batches/{uuid}")
+
+	// cancel only touches unfinished tasks
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/batches/b1/cancel", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	result, _ := testsuite.ParseJSONBody[dto.BulkOperation](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "PATCH /api/v1/batches/{uuid}/cancel")
+	assert.Len(t, result.Succeeded, 1, "PATCH /api/v1/batches/{uuid}/cancel")
+
+	// delete the whole batch
+	request = httptest.NewRequest(http.MethodDelete, "/api/v1/batches/b1", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	result, _ = testsuite.ParseJSONBody[dto.BulkOperation](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "DELETE /api/v1/batches/{uuid}")
+	assert.Len(t, result.Succeeded, 2, "DELETE /api/v1/batches/{uuid}")
+
+	request = httptest.NewRequest(http.MethodPatch, "/api/v1/batches/b1/restart", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "PATCH /api/v1/batches/{uuid}/restart")
+}
+
+func TestTaskEdit(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	pending := &model.Task{UUID: uuid.NewString(), Name: "pending", Status: dto.Paused, Command: &dto.RawResolved{Raw: "-i in.mov out.mp4"}, OutputFile: &dto.RawResolved{Raw: "/tmp/a.mp4"}, Priority: 1, Tags: []string{"gpu"}}
+	running := &model.Task{UUID: uuid.NewString(), Name: "running", Status: dto.Running, StartedAt: 1, ClientIdentifier: "elsewhere"}
+	finished := &model.Task{UUID: uuid.NewString(), Name: "finished", Status: dto.DoneSuccessful}
+	server.DB().Create([]*model.Task{pending, running, finished})
+
+	edit := func(uuid string, body string) (dto.Task, int) {
+		request := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/"+uuid, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		if response.StatusCode != http.StatusOK {
+			return dto.Task{}, response.StatusCode
+		}
+		task, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+		return task, response.StatusCode
+	}
+
+	task, status := edit(pending.UUID, `{"command":"-i in.mov -c:v libx265 out.mp4","outputFile":"/tmp/b.mp4","priority":7,"metadata":{"job":"42"}}`)
+	assert.Equal(t, http.StatusOK, status, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, pending.UUID, task.UUID, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, "pending", task.Name, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, "-i in.mov -c:v libx265 out.mp4", task.Command.Raw, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, "/tmp/b.mp4", task.OutputFile.Raw, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, uint(7), task.Priority, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, "42", (*task.Metadata)["job"], "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, []string{"gpu"}, task.Tags, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, dto.Paused, task.Status, "PATCH /api/v1/tasks/{uuid}")
+
+	task, status = edit(running.UUID, `{"priority":9}`)
+	assert.Equal(t, http.StatusOK, status, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, uint(9), task.Priority, "PATCH /api/v1/tasks/{uuid}")
+	assert.Equal(t, dto.Running, task.Status, "PATCH /api/v1/tasks/{uuid}")
+
+	_, status = edit(running.UUID, `{"command":"-y"}`)
+	assert.Equal(t, http.StatusBadRequest, status, "PATCH /api/v1/tasks/{uuid}")
+
+	_, status = edit(finished.UUID, `{"priority":9}`)
+	assert.Equal(t, http.StatusBadRequest, status, "PATCH /api/v1/tasks/{uuid}")
+
+	_, status = edit(uuid.NewString(), `{"priority":9}`)
+	assert.Equal(t, http.StatusBadRequest, status, "PATCH /api/v1/tasks/{uuid}")
+
+	_, status = edit(pending.UUID, `{"priority":-1}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status, "PATCH /api/v1/tasks/{uuid}")
+}
+
+func TestTaskResolve(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	body := `{"name":"resolve","command":"-i ${INPUT_FILE} -metadata title=\"${METADATA_title}\" ${OUTPUT_FILE} ${METADATA_missing} ${UNKNOWN}","inputFile":"/media/in.mov","outputFile":"${INPUT_FILE_DIR}/${INPUT_FILE_BASENAME}.mp4","metadata":{"title":"My Title"},"postProcessing":{"scriptPath":"/bin/notify ${OUTPUT_FILE_BASENAME}"}}`
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/resolve", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	resolved, _ := testsuite.ParseJSONBody[dto.ResolvedTask](response.Body)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, "/media/in.mp4", resolved.OutputFile, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, `-i "/media/in.mov" -metadata title="My Title" "/media/in.mp4"  ${UNKNOWN}`, resolved.Command, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, [][]string{{"-i", "/media/in.mov", "-metadata", "title=My Title", "/media/in.mp4", "${UNKNOWN}"}}, resolved.Argv, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, []string{"${METADATA_missing}", "${UNKNOWN}"}, resolved.UnknownWildcards, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, "/bin/notify in", resolved.PostProcessing.ScriptPath, "POST /api/v1/tasks/resolve")
+
+	// nothing has been queued
+	var count int64
+	server.DB().Model(&model.Task{}).Count(&count)
+	assert.Equal(t, int64(0), count, "POST /api/v1/tasks/resolve")
+
+	// the metadata of dependencies is known
+	response = createTask(t, server)
+	defer response.Body.Close() // nolint:errcheck
+	parent, _ := testsuite.ParseJSONBody[dto.Task](response.Body)
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/tasks/resolve", strings.NewReader(`{"name":"resolve","command":"-i ${METADATA_ffmate.dependencies.0.outputFile} -metadata parent=${METADATA_ffmate.dependencies.0.uuid}","dependsOn":["`+parent.UUID+`"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	resolved, _ = testsuite.ParseJSONBody[dto.ResolvedTask](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/tasks/resolve")
+	assert.Equal(t, "-i  -metadata parent="+parent.UUID, resolved.Command, "POST /api/v1/tasks/resolve")
+	assert.Empty(t, resolved.UnknownWildcards, "POST /api/v1/tasks/resolve")
+}
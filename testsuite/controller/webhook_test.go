@@ -3,13 +3,18 @@ package controller
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
 	"github.com/welovemedia/ffmate/v2/internal/dto"
 	"github.com/welovemedia/ffmate/v2/internal/service"
 	webhookSvc "github.com/welovemedia/ffmate/v2/internal/service/webhook"
@@ -218,3 +223,375 @@ func TestWebhookDirectDelivery(t *testing.T) {
 		t.Fatal("webhook was never delivered (timeout)")
 	}
 }
+
+func TestWebhookSignedDelivery(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	received := make(chan *http.Request, 2)
+	bodies := make(chan []byte, 2)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhookServer.Close() // nolint:errcheck
+
+	verify := func(r *http.Request, body []byte, secret string) {
+		expected := http.Header{}
+		ts, _ := strconv.ParseInt(r.Header.Get(webhookSvc.TimestampHeader), 10, 64)
+		webhookSvc.Sign(expected, secret, body, time.Unix(ts, 0))
+		assert.NotEmpty(t, r.Header.Get(webhookSvc.SignatureHeader), "RECV Webhook")
+		assert.Equal(t, expected.Get(webhookSvc.SignatureHeader), r.Header.Get(webhookSvc.SignatureHeader), "RECV Webhook")
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"), "RECV Webhook")
+	}
+	waitFor := func() (*http.Request, []byte) {
+		select {
+		case r := <-received:
+			return r, <-bodies
+		case <-time.After(2 * time.Second):
+			t.Fatal("webhook was never delivered (timeout)")
+		}
+		return nil, nil
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"event":"task.created","url":"`+webhookServer.URL+`","secret":"s3cret","headers":{"Authorization":"Bearer token"}}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ := io.ReadAll(response.Body)
+	webhook, _ := testsuite.ParseJSONBody[dto.Webhook](io.NopCloser(bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/webhooks")
+	assert.True(t, webhook.Signed, "POST /api/v1/webhooks")
+	assert.Equal(t, dto.MaskedHeader, webhook.Headers["Authorization"], "POST /api/v1/webhooks")
+	assert.NotContains(t, string(body), "s3cret", "POST /api/v1/webhooks")
+	assert.NotContains(t, string(body), "Bearer token", "POST /api/v1/webhooks")
+
+	// updating without a secret and with masked headers keeps the current ones
+	request = httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/"+webhook.UUID, strings.NewReader(`{"event":"task.created","url":"`+webhookServer.URL+`","headers":{"Authorization":"`+dto.MaskedHeader+`"}}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	webhook, _ = testsuite.ParseJSONBody[dto.Webhook](response.Body)
+	assert.True(t, webhook.Signed, "PUT /api/v1/webhooks/{uuid}")
+
+	svc := server.Service(service.Webhook).(*webhookSvc.Service)
+	svc.Fire(dto.TaskCreated, "")
+	r, b := waitFor()
+	verify(r, b, "s3cret")
+
+	// the recorded request contains masked headers only
+	assert.Eventually(t, func() bool {
+		var execution model.WebhookExecution
+		return server.DB().Where("webhook = ?", webhook.UUID).First(&execution).Error == nil &&
+			http.Header(execution.Request.Headers).Get("Authorization") == dto.MaskedHeader
+	}, 2*time.Second, 10*time.Millisecond, "RECV Webhook")
+
+	// direct webhooks
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/presets", strings.NewReader(`{"name":"signed","command":"-y","webhooks":[{"event":"preset.created","url":"`+webhookServer.URL+`","secret":"direct","headers":{"Authorization":"Bearer token"}}]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	body, _ = io.ReadAll(response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/presets")
+	assert.NotContains(t, string(body), "direct", "POST /api/v1/presets")
+	assert.NotContains(t, string(body), "Bearer token", "POST /api/v1/presets")
+
+	r, b = waitFor()
+	verify(r, b, "direct")
+	assert.NotContains(t, string(b), `"secret"`, "RECV Webhook")
+	assert.NotContains(t, string(b), "Bearer token", "RECV Webhook")
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	received := make(chan string, 1)
+	contentTypes := make(chan string, 1)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		contentTypes <- r.Header.Get("Content-Type")
+		received <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhookServer.Close() // nolint:errcheck
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"event":"preset.created","url":"`+webhookServer.URL+`","template":"{{.data.name","contentType":"text/plain"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/webhooks")
+
+	template, _ := json.Marshal(`{"text": {{json (printf "%s: preset %s created" .event .data.name)}}}`)
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"event":"preset.created","url":"`+webhookServer.URL+`","template":`+string(template)+`,"contentType":"application/vnd.slack+json"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	webhook, _ := testsuite.ParseJSONBody[dto.Webhook](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/webhooks")
+	assert.Equal(t, "application/vnd.slack+json", webhook.ContentType, "POST /api/v1/webhooks")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/presets", strings.NewReader(`{"name":"a \"quoted\" name","command":"-y"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+
+	select {
+	case body := <-received:
+		assert.Equal(t, "application/vnd.slack+json", <-contentTypes, "RECV Webhook")
+		assert.JSONEq(t, `{"text": "preset.created: preset a \"quoted\" name created"}`, body, "RECV Webhook")
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was never delivered (timeout)")
+	}
+
+	// direct webhooks are validated the same way
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/presets", strings.NewReader(`{"name":"invalid","command":"-y","webhooks":[{"event":"preset.created","url":"`+webhookServer.URL+`","template":"{{end}}"}]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/presets")
+}
+
+func TestWebhookDeliveryRetry(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	baseDelay := webhookSvc.RetryBaseDelay
+	webhookSvc.RetryBaseDelay = 10 * time.Millisecond
+	defer func() { webhookSvc.RetryBaseDelay = baseDelay }()
+
+	var calls atomic.Int32
+	delivered := make(chan struct{})
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dead" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// fail twice before accepting the delivery
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		close(delivered)
+	}))
+	defer webhookServer.Close() // nolint:errcheck
+
+	for _, body := range []string{
+		`{"event":"task.created","url":"` + webhookServer.URL + `/ok","maxAttempts":5}`,
+		`{"event":"task.created","url":"` + webhookServer.URL + `/dead","maxAttempts":2}`,
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/webhooks")
+	}
+
+	svc := server.Service(service.Webhook).(*webhookSvc.Service)
+	svc.Fire(dto.TaskCreated, "")
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was never delivered (timeout)")
+	}
+	assert.Equal(t, int32(3), calls.Load(), "RECV Webhook")
+
+	// the failing webhook ends up dead-lettered, the delivered one is removed from the queue
+	var deliveries []dto.WebhookDelivery
+	assert.Eventually(t, func() bool {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/deliveries", nil)
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		deliveries, _ = testsuite.ParseJSONBody[[]dto.WebhookDelivery](response.Body)
+		return len(deliveries) == 1 && deliveries[0].Status == dto.DeliveryDeadLetter
+	}, 5*time.Second, 50*time.Millisecond, "GET /api/v1/webhooks/deliveries")
+	assert.Equal(t, uint(2), deliveries[0].Attempts, "GET /api/v1/webhooks/deliveries")
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].ResponseStatus, "GET /api/v1/webhooks/deliveries")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/deliveries?status=PENDING", nil)
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	deliveries, _ = testsuite.ParseJSONBody[[]dto.WebhookDelivery](response.Body)
+	assert.Empty(t, deliveries, "GET /api/v1/webhooks/deliveries")
+
+	// every attempt is recorded as execution
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/executions", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	executions, _ := testsuite.ParseJSONBody[[]dto.WebhookExecution](response.Body)
+	assert.Len(t, executions, 5, "GET /api/v1/webhooks/executions")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"event":"task.created","url":"`+webhookServer.URL+`","timeout":600}`))
+	request.Header.Set("Content-Type", "application/json")
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "POST /api/v1/webhooks")
+}
+
+func TestWebhookRedeliverAndTest(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	var fail atomic.Bool
+	fail.Store(true)
+	received := make(chan *http.Request, 10)
+	bodies := make(chan string, 10)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhookServer.Close() // nolint:errcheck
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"event":"task.updated","url":"`+webhookServer.URL+`","secret":"s3cret","maxAttempts":1,"template":"{\"name\":{{json .data.name}}}"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	webhook, _ := testsuite.ParseJSONBody[dto.Webhook](response.Body)
+
+	// test-fire sends sample data
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+webhook.UUID+"/test", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	execution, _ := testsuite.ParseJSONBody[dto.WebhookExecution](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/webhooks/{uuid}/test")
+	assert.Equal(t, webhook.UUID, execution.Webhook, "POST /api/v1/webhooks/{uuid}/test")
+	assert.Equal(t, http.StatusInternalServerError, execution.Response.Status, "POST /api/v1/webhooks/{uuid}/test")
+	<-received
+	assert.JSONEq(t, `{"name":"Test task"}`, <-bodies, "RECV Webhook")
+
+	// a failing delivery is dead-lettered after its single attempt
+	svc := server.Service(service.Webhook).(*webhookSvc.Service)
+	svc.FireInRoutine(dto.TaskUpdated, &dto.Task{Name: "real"})
+	<-received
+	<-bodies
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/deliveries?status=DEAD_LETTER", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	deliveries, _ := testsuite.ParseJSONBody[[]dto.WebhookDelivery](response.Body)
+	assert.Len(t, deliveries, 1, "GET /api/v1/webhooks/deliveries")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/executions", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	executions, _ := testsuite.ParseJSONBody[[]dto.WebhookExecution](response.Body)
+	assert.Len(t, executions, 2, "GET /api/v1/webhooks/executions")
+	assert.Equal(t, deliveries[0].UUID, executions[0].Delivery, "GET /api/v1/webhooks/executions")
+
+	// redelivering resends the recorded payload, signed again, and removes the dead letter
+	fail.Store(false)
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/executions/"+executions[0].UUID+"/redeliver", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	execution, _ = testsuite.ParseJSONBody[dto.WebhookExecution](response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "POST /api/v1/webhooks/executions/{uuid}/redeliver")
+	assert.Equal(t, http.StatusNoContent, execution.Response.Status, "POST /api/v1/webhooks/executions/{uuid}/redeliver")
+	assert.Equal(t, uint(2), execution.Attempt, "POST /api/v1/webhooks/executions/{uuid}/redeliver")
+
+	r := <-received
+	body := <-bodies
+	assert.JSONEq(t, `{"name":"real"}`, body, "RECV Webhook")
+	expected := http.Header{}
+	ts, _ := strconv.ParseInt(r.Header.Get(webhookSvc.TimestampHeader), 10, 64)
+	webhookSvc.Sign(expected, "s3cret", []byte(body), time.Unix(ts, 0))
+	assert.Equal(t, expected.Get(webhookSvc.SignatureHeader), r.Header.Get(webhookSvc.SignatureHeader), "RECV Webhook")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/deliveries", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	deliveries, _ = testsuite.ParseJSONBody[[]dto.WebhookDelivery](response.Body)
+	assert.Empty(t, deliveries, "GET /api/v1/webhooks/deliveries")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/executions/unknown/redeliver", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/webhooks/executions/{uuid}/redeliver")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/unknown/test", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "POST /api/v1/webhooks/{uuid}/test")
+}
+
+func TestWebhookExecutionFilterAndStats(t *testing.T) {
+	server := testsuite.InitServer(t)
+
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhookServer.Close() // nolint:errcheck
+
+	var webhooks []dto.Webhook
+	for _, body := range []string{
+		`{"event":"task.updated","url":"` + webhookServer.URL + `/ok"}`,
+		`{"event":"task.updated","url":"` + webhookServer.URL + `/fail","maxAttempts":1}`,
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		webhook, _ := testsuite.ParseJSONBody[dto.Webhook](response.Body)
+		webhooks = append(webhooks, webhook)
+	}
+
+	svc := server.Service(service.Webhook).(*webhookSvc.Service)
+	svc.FireInRoutine(dto.TaskUpdated, &dto.Task{UUID: "task-1"})
+	svc.FireInRoutine(dto.TaskUpdated, &dto.Task{UUID: "task-2"})
+
+	listExecutions := func(query string) []dto.WebhookExecution {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/executions?"+query, nil)
+		response := server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/webhooks/executions?"+query)
+		executions, _ := testsuite.ParseJSONBody[[]dto.WebhookExecution](response.Body)
+		return executions
+	}
+
+	assert.Len(t, listExecutions(""), 4, "GET /api/v1/webhooks/executions")
+	executions := listExecutions("task=task-1")
+	assert.Len(t, executions, 2, "GET /api/v1/webhooks/executions?task")
+	assert.Equal(t, "task-1", executions[0].Task, "GET /api/v1/webhooks/executions?task")
+	executions = listExecutions("webhook=" + webhooks[1].UUID + "&statusFrom=500&statusTo=599")
+	assert.Len(t, executions, 2, "GET /api/v1/webhooks/executions?status")
+	assert.Equal(t, webhooks[1].UUID, executions[0].Webhook, "GET /api/v1/webhooks/executions?status")
+	assert.Empty(t, listExecutions("event=task.created"), "GET /api/v1/webhooks/executions?event")
+	assert.Empty(t, listExecutions("createdFrom="+strconv.FormatInt(time.Now().Add(time.Hour).UnixMilli(), 10)), "GET /api/v1/webhooks/executions?createdFrom")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/executions?statusFrom=42", nil)
+	response := server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode, "GET /api/v1/webhooks/executions?statusFrom")
+
+	for i, expected := range []float64{1, 0} {
+		request = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+webhooks[i].UUID+"/stats", nil)
+		response = server.TestRequest(request)
+		defer response.Body.Close() // nolint:errcheck
+		stats, _ := testsuite.ParseJSONBody[dto.WebhookStats](response.Body)
+		assert.Equal(t, http.StatusOK, response.StatusCode, "GET /api/v1/webhooks/{uuid}/stats")
+		assert.Equal(t, int64(2), stats.Executions, "GET /api/v1/webhooks/{uuid}/stats")
+		assert.Equal(t, expected, stats.SuccessRate, "GET /api/v1/webhooks/{uuid}/stats")
+		assert.GreaterOrEqual(t, stats.LatencyP95, stats.LatencyP50, "GET /api/v1/webhooks/{uuid}/stats")
+		if expected == 0 {
+			assert.Equal(t, int64(2), stats.Failed, "GET /api/v1/webhooks/{uuid}/stats")
+			assert.Equal(t, "task-2", stats.LastFailure.Task, "GET /api/v1/webhooks/{uuid}/stats")
+		} else {
+			assert.Nil(t, stats.LastFailure, "GET /api/v1/webhooks/{uuid}/stats")
+		}
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/unknown/stats", nil)
+	response = server.TestRequest(request)
+	defer response.Body.Close() // nolint:errcheck
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, "GET /api/v1/webhooks/{uuid}/stats")
+}
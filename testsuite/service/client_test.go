@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/database/repository"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/client"
+	"github.com/welovemedia/ffmate/v2/testsuite"
+)
+
+func TestReapStaleClients(t *testing.T) {
+	server := testsuite.InitServer(t)
+	clientRepository := &repository.Client{DB: server.DB()}
+	taskRepository := &repository.Task{DB: server.DB()}
+
+	_, _ = clientRepository.Add(&model.Client{Identifier: "stale-client", LastSeen: time.Now().Add(-time.Hour).UnixMilli()})
+	task, _ := taskRepository.Add(&model.Task{UUID: uuid.NewString(), Status: dto.Running, StartedAt: 1, Attempt: 1, ClientIdentifier: "stale-client"})
+
+	svc := server.Service(service.Client).(*client.Service)
+	svc.ReapStaleClients()
+
+	stale, _ := clientRepository.FirstByIdentifier("stale-client")
+	assert.True(t, stale.Offline, "ReapStaleClients")
+
+	task, _ = taskRepository.First(task.UUID)
+	assert.Equal(t, dto.Queued, task.Status, "ReapStaleClients")
+	assert.Equal(t, uint(0), task.Attempt, "ReapStaleClients")
+
+	// marking offline happens only once
+	marked, _ := clientRepository.MarkOffline("stale-client")
+	assert.False(t, marked, "MarkOffline")
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/database/repository"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	"github.com/welovemedia/ffmate/v2/internal/service/preset"
+	"github.com/welovemedia/ffmate/v2/internal/service/schedule"
+	"github.com/welovemedia/ffmate/v2/testsuite"
+	"gorm.io/gorm"
+)
+
+func TestProcessDueSchedules(t *testing.T) {
+	server := testsuite.InitServer(t)
+	scheduleRepository := &repository.Schedule{DB: server.DB()}
+	taskRepository := &repository.Task{DB: server.DB()}
+
+	p, _ := server.Service(service.Preset).(*preset.Service).Add(&dto.NewPreset{Name: "archive", Command: "-i ${INPUT_FILE} ${OUTPUT_FILE}"})
+	due, _ := scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "nightly", Cron: "0 2 * * *", Preset: p.UUID, InputFile: "/archive/in.mov", NextRun: time.Now().Add(-time.Minute).UnixMilli()})
+	_, _ = scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "later", Cron: "0 2 * * *", Preset: p.UUID, NextRun: time.Now().Add(time.Hour).UnixMilli()})
+	_, _ = scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "suspended", Cron: "0 2 * * *", Preset: p.UUID, NextRun: time.Now().Add(-time.Minute).UnixMilli(), Suspended: true})
+
+	svc := server.Service(service.Schedule).(*schedule.Service)
+	svc.ProcessDue()
+
+	tasks, total, _ := taskRepository.List(0, 10, nil)
+	assert.Equal(t, int64(1), total, "ProcessDue")
+	assert.Equal(t, dto.SCHEDULE, (*tasks)[0].Source, "ProcessDue")
+	assert.Equal(t, "nightly", (*tasks)[0].Name, "ProcessDue")
+	assert.Equal(t, "/archive/in.mov", (*tasks)[0].InputFile.Raw, "ProcessDue")
+
+	s, _ := scheduleRepository.First(due.UUID)
+	assert.NotZero(t, s.LastRun, "ProcessDue")
+	assert.Greater(t, s.NextRun, time.Now().UnixMilli(), "ProcessDue")
+
+	// a run is only claimed once
+	claimed, _ := scheduleRepository.Claim(due, due.LastRun, due.NextRun)
+	assert.False(t, claimed, "Claim")
+}
+
+func TestProcessDueScheduleGlob(t *testing.T) {
+	server := testsuite.InitServer(t)
+	taskRepository := &repository.Task{DB: server.DB()}
+	svc := server.Service(service.Schedule).(*schedule.Service)
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.mov", "b.mov", "c.wav"} {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644)
+	}
+	_ = os.Mkdir(filepath.Join(dir, "d.mov"), 0o755)
+
+	p, _ := server.Service(service.Preset).(*preset.Service).Add(&dto.NewPreset{Name: "archive", Command: "-i ${INPUT_FILE} ${OUTPUT_FILE}"})
+
+	// directories and malformed patterns are rejected
+	_, err := svc.Add(&dto.NewSchedule{Name: "folder", Cron: "0 2 * * *", Preset: p.UUID, InputFile: dir})
+	assert.Error(t, err, "Add")
+	_, err = svc.Add(&dto.NewSchedule{Name: "pattern", Cron: "0 2 * * *", Preset: p.UUID, InputFile: filepath.Join(dir, "[.mov")})
+	assert.Error(t, err, "Add")
+
+	s, err := svc.Add(&dto.NewSchedule{Name: "glob", Cron: "0 2 * * *", Preset: p.UUID, InputFile: filepath.Join(dir, "*.mov")})
+	assert.NoError(t, err, "Add")
+	server.DB().Model(&model.Schedule{}).Where("uuid = ?", s.UUID).Update("next_run", time.Now().Add(-time.Minute).UnixMilli())
+
+	svc.ProcessDue()
+
+	tasks, total, _ := taskRepository.List(0, 10, &dto.TaskFilter{Sort: "name", Order: "asc"})
+	assert.Equal(t, int64(2), total, "ProcessDue")
+	var inputs []string
+	for _, task := range *tasks {
+		inputs = append(inputs, task.InputFile.Raw)
+	}
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "a.mov"), filepath.Join(dir, "b.mov")}, inputs, "ProcessDue")
+}
+
+func TestProcessDueSchedulePriority(t *testing.T) {
+	server := testsuite.InitServer(t)
+	scheduleRepository := &repository.Schedule{DB: server.DB()}
+	taskRepository := &repository.Task{DB: server.DB()}
+
+	p, _ := server.Service(service.Preset).(*preset.Service).Add(&dto.NewPreset{Name: "archive", Command: "-i ${INPUT_FILE} ${OUTPUT_FILE}", Priority: 50})
+
+	// an explicit priority of 0 is forwarded, an unset one falls back to the preset
+	priority := uint(0)
+	_, _ = scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "explicit", Cron: "0 2 * * *", Preset: p.UUID, Priority: &priority, NextRun: time.Now().Add(-time.Minute).UnixMilli()})
+	_, _ = scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "unset", Cron: "0 2 * * *", Preset: p.UUID, NextRun: time.Now().Add(-time.Minute).UnixMilli()})
+
+	server.Service(service.Schedule).(*schedule.Service).ProcessDue()
+
+	tasks, total, _ := taskRepository.List(0, 10, &dto.TaskFilter{Sort: "name", Order: "asc"})
+	assert.Equal(t, int64(2), total, "ProcessDue")
+	assert.Equal(t, "explicit", (*tasks)[0].Name, "ProcessDue")
+	assert.Equal(t, uint(0), (*tasks)[0].Priority, "ProcessDue")
+	assert.Equal(t, "unset", (*tasks)[1].Name, "ProcessDue")
+	assert.Equal(t, uint(50), (*tasks)[1].Priority, "ProcessDue")
+}
+
+func TestProcessDueKeepsConcurrentEdits(t *testing.T) {
+	server := testsuite.InitServer(t)
+	scheduleRepository := &repository.Schedule{DB: server.DB()}
+
+	p, _ := server.Service(service.Preset).(*preset.Service).Add(&dto.NewPreset{Name: "archive", Command: "-i ${INPUT_FILE} ${OUTPUT_FILE}"})
+	due, _ := scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "nightly", Cron: "0 2 * * *", Preset: p.UUID, InputFile: "/archive/in.mov", NextRun: time.Now().Add(-time.Minute).UnixMilli()})
+	broken, _ := scheduleRepository.Add(&model.Schedule{UUID: uuid.NewString(), Name: "broken", Cron: "0 2 * * *", Preset: "unknown", InputFile: "/archive/in.mov", NextRun: time.Now().Add(-time.Minute).UnixMilli()})
+
+	// the schedule is suspended while its tasks are being created
+	_ = server.DB().Callback().Create().Before("gorm:create").Register("test:suspend_schedule", func(db *gorm.DB) {
+		if db.Statement.Schema != nil && db.Statement.Schema.Name == "Task" {
+			db.Session(&gorm.Session{NewDB: true}).Model(&model.Schedule{}).Where("uuid = ?", due.UUID).Update("suspended", true)
+		}
+	})
+
+	server.Service(service.Schedule).(*schedule.Service).ProcessDue()
+
+	s, _ := scheduleRepository.First(due.UUID)
+	assert.True(t, s.Suspended, "ProcessDue")
+	assert.Empty(t, s.Error, "ProcessDue")
+	assert.NotZero(t, s.LastRun, "ProcessDue")
+
+	s, _ = scheduleRepository.First(broken.UUID)
+	assert.NotEmpty(t, s.Error, "ProcessDue")
+	assert.NotZero(t, s.LastRun, "ProcessDue")
+}
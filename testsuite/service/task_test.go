@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/welovemedia/ffmate/v2/internal/cfg"
+	"github.com/welovemedia/ffmate/v2/internal/database/model"
+	"github.com/welovemedia/ffmate/v2/internal/dto"
+	"github.com/welovemedia/ffmate/v2/internal/service"
+	taskService "github.com/welovemedia/ffmate/v2/internal/service/task"
+	"github.com/welovemedia/ffmate/v2/testsuite"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5/util/typeutil"
+)
+
+func TestAddFailsWithDependencyFailedBeforeInsert(t *testing.T) {
+	server := testsuite.InitServer(t)
+	svc := server.Service(service.Task).(*taskService.Service)
+
+	parent, err := svc.Add(context.Background(), &dto.NewTask{Name: "parent", Command: "-y"}, dto.API, "")
+	assert.NoError(t, err, "Add")
+
+	// the parent fails after the child has been validated but before it is inserted
+	var parentFailed bool
+	_ = server.DB().Callback().Create().Before("gorm:create").Register("test:fail_parent", func(db *gorm.DB) {
+		if parentFailed || db.Statement.Schema == nil || db.Statement.Schema.Name != "Task" {
+			return
+		}
+		parentFailed = true
+		db.Session(&gorm.Session{NewDB: true}).Model(&model.Task{}).Where("uuid = ?", parent.UUID).Update("status", dto.DoneError)
+	})
+
+	child, err := svc.Add(context.Background(), &dto.NewTask{Name: "child", Command: "-y", DependsOn: []string{parent.UUID}}, dto.API, "")
+	assert.NoError(t, err, "Add")
+	assert.True(t, parentFailed, "Add")
+	assert.Equal(t, dto.DoneError, child.Status, "Add")
+	assert.Contains(t, child.Error, parent.UUID, "Add")
+}
+
+func TestPauseQueuedClaimedInTheMeantime(t *testing.T) {
+	server := testsuite.InitServer(t)
+	svc := server.Service(service.Task).(*taskService.Service)
+
+	task, _ := svc.Add(context.Background(), &dto.NewTask{Name: "claimed", Command: "-y"}, dto.API, "")
+
+	// a node claims the task after it has been read but before the pause is saved
+	var claimed bool
+	_ = server.DB().Callback().Update().Before("gorm:update").Register("test:claim_task", func(db *gorm.DB) {
+		if claimed || db.Statement.Schema == nil || db.Statement.Schema.Name != "Task" {
+			return
+		}
+		claimed = true
+		db.Session(&gorm.Session{NewDB: true}).Model(&model.Task{}).Where("uuid = ?", task.UUID).UpdateColumn("status", dto.Running)
+	})
+
+	_, err := svc.Pause(task.UUID)
+	assert.Error(t, err, "Pause")
+	assert.True(t, claimed, "Pause")
+
+	task, _ = svc.Get(task.UUID)
+	assert.Equal(t, dto.Running, task.Status, "Pause")
+}
+
+func TestEditPriorityOfProcessedTask(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+	server := testsuite.InitServer(t)
+	svc := server.Service(service.Task).(*taskService.Service)
+
+	// fake ffmpeg keeping the task running for a moment
+	bin := filepath.Join(t.TempDir(), "ffmpeg")
+	assert.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\nsleep 1\n"), 0755))
+	cfg.Set("ffmate.ffmpeg", bin)
+	cfg.Set("ffmate.isFFmpeg", true)
+	defer cfg.Set("ffmate.isFFmpeg", false)
+
+	task, err := svc.Add(context.Background(), &dto.NewTask{Name: "processed", Command: "-y", OutputFile: filepath.Join(t.TempDir(), "out.mp4"), Priority: typeutil.NewUndefined[uint](1)}, dto.API, "")
+	assert.NoError(t, err, "Add")
+	assert.Eventually(t, func() bool {
+		task, _ = svc.Get(task.UUID)
+		return task.Status == dto.Running
+	}, 5*time.Second, 10*time.Millisecond, "processing")
+
+	task, err = svc.Edit(task.UUID, &dto.UpdateTask{Priority: typeutil.NewUndefined[uint](9)})
+	assert.NoError(t, err, "Edit")
+	assert.Equal(t, uint(9), task.Priority, "Edit")
+
+	// the processing node saves its copy of the task claimed with the former priority
+	assert.Eventually(t, func() bool {
+		task, _ = svc.Get(task.UUID)
+		return task.Status == dto.DoneSuccessful
+	}, 5*time.Second, 10*time.Millisecond, "processing")
+	assert.Equal(t, uint(9), task.Priority, "processing")
+}
@@ -21,6 +21,8 @@ var c = map[string]any{
 	"database": map[string]any{
 		"connection": "sqlite3",
 		"name":       ":memory:",
+		// every connection opens its own in-memory database, so background routines must share a single one
+		"maxOpenConnections": 1,
 	},
 }
 